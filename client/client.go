@@ -0,0 +1,240 @@
+// Package client is a Go client of the subscriptions API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/n-korel/user-subscriptions-api/internal/subscriptions"
+)
+
+// Request and response types shared with the server.
+type (
+	Subscription              = subscriptions.Subscription
+	SubscriptionList          = subscriptions.SubscriptionList
+	CreateSubscriptionRequest = subscriptions.CreateSubscriptionRequest
+	UpdateSubscriptionRequest = subscriptions.UpdateSubscriptionRequest
+	CostResponse              = subscriptions.CostResponse
+	Sort                      = subscriptions.Sort
+	SubscriptionFilter        = subscriptions.SubscriptionFilter
+)
+
+var (
+	// ErrNotFound matches errors of requests answered 404 Not Found.
+	ErrNotFound = subscriptions.ErrNotFound
+
+	// ErrInvalidRequest matches errors of requests answered 400 Bad Request.
+	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrConflict matches errors of requests answered 409 Conflict, such as a
+	// duplicate external_id.
+	ErrConflict = errors.New("conflict")
+)
+
+// Error is an unexpected response of the API. Message is the error of the
+// response envelope, or the status text when the body has none.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("subscriptions API: %d %s", e.StatusCode, e.Message)
+}
+
+// Is maps the status code to ErrNotFound, ErrInvalidRequest or ErrConflict.
+func (e *Error) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusBadRequest:
+		return target == ErrInvalidRequest
+	case http.StatusConflict:
+		return target == ErrConflict
+	default:
+		return false
+	}
+}
+
+// Client calls the subscriptions API at a base URL such as
+// http://localhost:8080.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sends requests with httpClient instead of http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New returns a client of the API at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// envelope is the JSON envelope of responses, see subscriptions.Response.
+type envelope struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	Error     string          `json:"error"`
+	Truncated bool            `json:"truncated"`
+}
+
+// List returns the subscriptions in sort order. Truncated is set when the
+// server cut the listing at its hard cap.
+func (c *Client) List(ctx context.Context, sort Sort) (*SubscriptionList, error) {
+	query := url.Values{}
+	if sort.Field != "" {
+		query.Set("sort_by", sort.Field)
+	}
+	if sort.Order != "" {
+		query.Set("order", sort.Order)
+	}
+
+	var subs []Subscription
+	env, err := c.do(ctx, http.MethodGet, "/v1/subscriptions", query, nil, http.StatusOK, &subs)
+	if err != nil {
+		return nil, err
+	}
+	return &SubscriptionList{Subscriptions: subs, Truncated: env.Truncated}, nil
+}
+
+func (c *Client) Get(ctx context.Context, id int) (*Subscription, error) {
+	var sub Subscription
+	if _, err := c.do(ctx, http.MethodGet, subscriptionPath(id), nil, nil, http.StatusOK, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (c *Client) Create(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+	var sub Subscription
+	if _, err := c.do(ctx, http.MethodPost, "/v1/subscriptions", nil, req, http.StatusCreated, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (c *Client) Update(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error) {
+	var sub Subscription
+	if _, err := c.do(ctx, http.MethodPatch, subscriptionPath(id), nil, req, http.StatusOK, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Delete deletes the subscription. Unless the server runs in strict delete
+// mode, deleting a missing subscription also succeeds.
+func (c *Client) Delete(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, subscriptionPath(id), nil, nil, http.StatusNoContent, nil)
+	return err
+}
+
+// Cost returns the total cost of the subscriptions matching filter, see
+// GET /v1/subscriptions/cost.
+func (c *Client) Cost(ctx context.Context, filter SubscriptionFilter) (*CostResponse, error) {
+	query := url.Values{}
+	if filter.StartDate != "" {
+		query.Set("start_date", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		query.Set("end_date", filter.EndDate)
+	}
+	if filter.UserID != nil {
+		query.Set("user_id", filter.UserID.String())
+	}
+	if filter.ServiceName != nil {
+		query.Set("service_name", *filter.ServiceName)
+	}
+
+	var cost CostResponse
+	if _, err := c.do(ctx, http.MethodGet, "/v1/subscriptions/cost", query, nil, http.StatusOK, &cost); err != nil {
+		return nil, err
+	}
+	return &cost, nil
+}
+
+func subscriptionPath(id int) string {
+	return "/v1/subscriptions/" + strconv.Itoa(id)
+}
+
+// do sends the request with body encoded as JSON, if any, and decodes the data
+// of the response envelope into out. A status other than wantStatus is
+// returned as an *Error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, wantStatus int, out any) (*envelope, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var env envelope
+	decodeErr := json.Unmarshal(respBody, &env)
+
+	if resp.StatusCode != wantStatus {
+		apiErr := &Error{StatusCode: resp.StatusCode, Message: env.Error}
+		if decodeErr != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, apiErr
+	}
+
+	if out == nil {
+		return &env, nil
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return nil, fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return &env, nil
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/n-korel/user-subscriptions-api/internal/subscriptions"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, fields map[string]any)  {}
+func (m *MockLogger) Error(message string, fields map[string]any) {}
+func (m *MockLogger) Warn(message string, fields map[string]any)  {}
+func (m *MockLogger) Debug(message string, fields map[string]any) {}
+func (m *MockLogger) Fatal(message string, fields map[string]any) {}
+func (m *MockLogger) Sync() error                                 { return nil }
+
+// memoryService keeps subscriptions in memory. Methods the client does not
+// call are left to the embedded nil SubscriptionService and panic.
+type memoryService struct {
+	subscriptions.SubscriptionService
+
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]Subscription
+}
+
+func newMemoryService() *memoryService {
+	return &memoryService{nextID: 1, subs: make(map[int]Subscription)}
+}
+
+func (s *memoryService) ListSubscriptions(ctx context.Context, sort Sort) (*SubscriptionList, error) {
+	if sort.Order != "" && sort.Order != "asc" && sort.Order != "desc" {
+		return nil, &subscriptions.ValidationError{Field: "order", Message: "order must be asc or desc"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := &SubscriptionList{Subscriptions: []Subscription{}}
+	for id := 1; id < s.nextID; id++ {
+		if sub, ok := s.subs[id]; ok {
+			list.Subscriptions = append(list.Subscriptions, sub)
+		}
+	}
+	return list, nil
+}
+
+func (s *memoryService) GetSubscriptionByID(ctx context.Context, id int) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil, subscriptions.ErrNotFound
+	}
+	return &sub, nil
+}
+
+func (s *memoryService) CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sub := range s.subs {
+		if req.ExternalID != nil && sub.ExternalID != nil && *sub.ExternalID == *req.ExternalID {
+			return nil, subscriptions.ErrExternalIDExists
+		}
+	}
+
+	sub := Subscription{
+		ID:          s.nextID,
+		ServiceName: req.ServiceName,
+		Price:       req.Price,
+		UserID:      req.UserID,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		ExternalID:  req.ExternalID,
+		CreatedAt:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	s.subs[sub.ID] = sub
+	s.nextID++
+	return &sub, nil
+}
+
+func (s *memoryService) UpdateSubscription(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil, subscriptions.ErrNotFound
+	}
+	sub.ServiceName = req.ServiceName
+	sub.Price = req.Price
+	sub.StartDate = req.StartDate
+	sub.EndDate = req.EndDate
+	s.subs[id] = sub
+	return &sub, nil
+}
+
+func (s *memoryService) DeleteSubscription(ctx context.Context, id int) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil, subscriptions.ErrNotFound
+	}
+	delete(s.subs, id)
+	return &sub, nil
+}
+
+func (s *memoryService) GetCostVersion(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*subscriptions.CostVersion, error) {
+	if startDate == "" {
+		return nil, &subscriptions.ValidationError{Field: "start_date", Message: "at least one date parameter is required"}
+	}
+	return &subscriptions.CostVersion{StartDate: startDate, EndDate: endDate}, nil
+}
+
+func (s *memoryService) GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var cost CostResponse
+	for _, sub := range s.subs {
+		if userID != nil && sub.UserID != *userID {
+			continue
+		}
+		if serviceName != nil && sub.ServiceName != *serviceName {
+			continue
+		}
+		cost.TotalCost += int64(sub.Price)
+		cost.Count++
+	}
+	return &cost, nil
+}
+
+func newTestClient(t *testing.T, opts ...subscriptions.HandlerOption) *Client {
+	t.Helper()
+
+	r := chi.NewRouter()
+	subscriptions.NewHandler(newMemoryService(), &MockLogger{}, opts...).RegisterRoutes(r)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	return New(server.URL+"/", WithHTTPClient(server.Client()))
+}
+
+func TestClient_CRUD(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	externalID := "crm-1"
+
+	created, err := c.Create(ctx, CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       400,
+		UserID:      userID,
+		StartDate:   "01-2025",
+		ExternalID:  &externalID,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, created.ID)
+	assert.Equal(t, "Netflix", created.ServiceName)
+	assert.Equal(t, &externalID, created.ExternalID)
+
+	got, err := c.Get(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, created, got)
+
+	endDate := "12-2025"
+	updated, err := c.Update(ctx, created.ID, UpdateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       500,
+		UserID:      userID,
+		StartDate:   "01-2025",
+		EndDate:     &endDate,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 500, updated.Price)
+	assert.Equal(t, &endDate, updated.EndDate)
+
+	list, err := c.List(ctx, Sort{Field: "price", Order: "desc"})
+	assert.NoError(t, err)
+	assert.False(t, list.Truncated)
+	assert.Equal(t, []Subscription{*updated}, list.Subscriptions)
+
+	assert.NoError(t, c.Delete(ctx, created.ID))
+
+	_, err = c.Get(ctx, created.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClient_Cost(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	for _, req := range []CreateSubscriptionRequest{
+		{ServiceName: "Netflix", Price: 400, UserID: userID, StartDate: "01-2025"},
+		{ServiceName: "Spotify", Price: 200, UserID: userID, StartDate: "01-2025"},
+		{ServiceName: "Netflix", Price: 400, UserID: uuid.New(), StartDate: "01-2025"},
+	} {
+		if _, err := c.Create(ctx, req); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+
+	cost, err := c.Cost(ctx, SubscriptionFilter{StartDate: "01-2025", EndDate: "12-2025", UserID: &userID})
+	assert.NoError(t, err)
+	assert.Equal(t, &CostResponse{TotalCost: 600, Count: 2}, cost)
+
+	serviceName := "Netflix"
+	cost, err = c.Cost(ctx, SubscriptionFilter{StartDate: "01-2025", ServiceName: &serviceName})
+	assert.NoError(t, err)
+	assert.Equal(t, &CostResponse{TotalCost: 800, Count: 2}, cost)
+}
+
+func TestClient_Errors(t *testing.T) {
+	c := newTestClient(t, subscriptions.WithStrictDelete(true), subscriptions.WithDisabledEndpoints("cost"))
+	ctx := context.Background()
+
+	externalID := "crm-1"
+	req := CreateSubscriptionRequest{ServiceName: "Netflix", Price: 400, UserID: uuid.New(), StartDate: "01-2025", ExternalID: &externalID}
+	_, err := c.Create(ctx, req)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		call    func() error
+		status  int
+		message string
+		is      error
+	}{
+		{
+			name: "duplicate external ID",
+			call: func() error {
+				_, err := c.Create(ctx, req)
+				return err
+			},
+			status:  http.StatusConflict,
+			message: "external_id already exists",
+			is:      ErrConflict,
+		},
+		{
+			name: "invalid sort",
+			call: func() error {
+				_, err := c.List(ctx, Sort{Order: "sideways"})
+				return err
+			},
+			status:  http.StatusBadRequest,
+			message: "order must be asc or desc",
+			is:      ErrInvalidRequest,
+		},
+		{
+			name:    "strict delete of a missing subscription",
+			call:    func() error { return c.Delete(ctx, 42) },
+			status:  http.StatusNotFound,
+			message: "subscription not found",
+			is:      ErrNotFound,
+		},
+		{
+			name: "response without envelope",
+			call: func() error {
+				_, err := c.Cost(ctx, SubscriptionFilter{StartDate: "01-2025"})
+				return err
+			},
+			status:  http.StatusNotFound,
+			message: "Not Found",
+			is:      ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+
+			var apiErr *Error
+			if assert.ErrorAs(t, err, &apiErr) {
+				assert.Equal(t, tt.status, apiErr.StatusCode)
+				assert.Equal(t, tt.message, apiErr.Message)
+			}
+			assert.ErrorIs(t, err, tt.is)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/n-korel/user-subscriptions-api/internal/config"
+)
+
+// startupFields summarizes the resolved configuration for the startup log
+// line. Connection strings come from cfg.Redacted and the debug and admin API
+// keys are reported only as set or not.
+func startupFields(cfg config.Config, poolMaxConns int32) map[string]any {
+	redacted := cfg.Redacted()
+
+	return map[string]any{
+		"port":                     cfg.ServerPort,
+		"log_level":                cfg.LogLevel,
+		"log_tz":                   cfg.LogTZ,
+		"dsn":                      redacted.DSN,
+		"read_dsn":                 redacted.ReadDSN,
+		"pool_max_conns":           poolMaxConns,
+		"cors_allowed_origins":     redacted.CORS.AllowedOrigins,
+		"trusted_proxies":          redacted.TrustedProxies,
+		"disabled_endpoints":       redacted.DisabledEndpoints,
+		"exempt_routes":            redacted.ExemptRoutes,
+		"debug_endpoints":          cfg.DebugAPIKey != "",
+		"admin_endpoints":          cfg.AdminAPIKey != "",
+		"strict_delete":            cfg.StrictDelete,
+		"reject_past_start":        cfg.RejectPastStart,
+		"cost_deduplication":       cfg.CostDeduplication,
+		"cost_max_concurrency":     cfg.CostMaxConcurrency,
+		"cost_max_months":          cfg.CostMaxMonths,
+		"getall_hard_cap":          cfg.GetAllHardCap,
+		"default_duration_months":  cfg.DefaultDurationMonths,
+		"load_shed_wait_threshold": cfg.LoadShedWaitThreshold.String(),
+		"request_timeout":          cfg.RequestTimeout.String(),
+		"endpoint_timeouts":        redacted.EndpointTimeouts,
+		"reminder_interval":        cfg.ReminderInterval.String(),
+		"stats_refresh_interval":   cfg.StatsRefreshInterval.String(),
+		"summary_refresh_interval": cfg.SummaryRefreshInterval.String(),
+	}
+}
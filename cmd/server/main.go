@@ -2,18 +2,29 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
-	_ "github.com/n-korel/user-subscriptions-api/docs" // swagger docs
+	"github.com/n-korel/user-subscriptions-api/internal/config"
+	"github.com/n-korel/user-subscriptions-api/internal/debug"
+	"github.com/n-korel/user-subscriptions-api/internal/health"
+	"github.com/n-korel/user-subscriptions-api/internal/index"
 	"github.com/n-korel/user-subscriptions-api/internal/logger"
+	"github.com/n-korel/user-subscriptions-api/internal/middleware"
+	"github.com/n-korel/user-subscriptions-api/internal/reminders"
 	"github.com/n-korel/user-subscriptions-api/internal/subscriptions"
-	httpSwagger "github.com/swaggo/http-swagger/v2"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 //	@title			User Subscriptions API
@@ -28,30 +39,46 @@ import (
 //	@license.name	MIT
 //	@license.url	https://opensource.org/licenses/MIT
 
+//	@securityDefinitions.apikey	AdminAPIKey
+//	@in							header
+//	@name						X-API-Key
+
+const (
+	// shutdownTimeout bounds how long in-flight requests and queued reminders
+	// may take to finish on shutdown.
+	shutdownTimeout = 10 * time.Second
+
+	// dbSlowAfter is the ping latency above which the database is reported degraded.
+	dbSlowAfter = 100 * time.Millisecond
+
+	// replicaCheckInterval is how often the read replica is pinged to decide
+	// whether reads fall back to the primary.
+	replicaCheckInterval = 5 * time.Second
+)
+
 // @host		localhost:8080
 // @BasePath	/v1
 func main() {
 	if err := godotenv.Load(); err != nil {
-		fmt.Println("Warning: .env file not found")
+		fmt.Fprintln(os.Stderr, "Warning: .env file not found")
 	}
 
-	dsn := os.Getenv("DSN")
-	if dsn == "" {
-		fmt.Println("FATAL: DSN environment variable is not set")
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("FATAL: %v\n", err)
 		os.Exit(1)
 	}
 
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = "8080"
+	// A subcommand, such as "report", runs once instead of the HTTP server.
+	if len(os.Args) > 1 {
+		if err := runCommand(context.Background(), os.Args[1], os.Args[2:], cfg, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "info"
-	}
-
-	log, err := logger.New(logLevel)
+	log, err := logger.New(cfg.LogLevel, loggerOptions(cfg)...)
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
@@ -62,36 +89,185 @@ func main() {
 	}()
 
 
-	db, err := pgxpool.New(context.Background(), dsn)
+	db, err := pgxpool.New(context.Background(), cfg.DSN)
 	if err != nil {
 		log.Fatal("Failed to connect to database", map[string]any{"error": err})
 	}
 	defer db.Close()
 
+	log.Info("Starting with configuration", startupFields(cfg, db.Config().MaxConns))
+
 	if err := db.Ping(context.Background()); err != nil {
 		log.Fatal("Failed to ping database", map[string]any{"error": err})
 	}
 
 	log.Info("Database has connected!", nil)
 
-	repo := subscriptions.NewRepository(db, log)
-	service := subscriptions.NewService(repo, log)
-	handler := subscriptions.NewHandler(service, log)
+	healthChecks := []health.Check{
+		{Name: "database", Critical: true, SlowAfter: dbSlowAfter, Run: db.Ping},
+	}
+
+	var repoOpts []subscriptions.RepositoryOption
+	var replicaMonitor *health.Monitor
+	if cfg.ReadDSN != "" {
+		readDB, err := pgxpool.New(context.Background(), cfg.ReadDSN)
+		if err != nil {
+			log.Fatal("Failed to connect to read replica", map[string]any{"error": err})
+		}
+		defer readDB.Close()
+
+		// An unreachable replica is not fatal: reads use the primary until it recovers.
+		if err := readDB.Ping(context.Background()); err != nil {
+			log.Warn("Failed to ping read replica, reading from the primary", map[string]any{"error": err})
+		} else {
+			log.Info("Read replica has connected!", nil)
+		}
+
+		replicaMonitor = health.NewMonitor("database_replica", readDB.Ping, replicaCheckInterval, log)
+		repoOpts = append(repoOpts,
+			subscriptions.WithReadDB(readDB),
+			subscriptions.WithReadDBFallback(replicaMonitor.Healthy),
+		)
+		healthChecks = append(healthChecks, health.Check{Name: "database_replica", SlowAfter: dbSlowAfter, Run: readDB.Ping})
+	}
+
+	serviceOpts := []subscriptions.ServiceOption{
+		subscriptions.WithMaxCostMonths(cfg.CostMaxMonths),
+		subscriptions.WithListHardCap(cfg.GetAllHardCap),
+	}
+	if cfg.DefaultDurationMonths > 0 {
+		serviceOpts = append(serviceOpts, subscriptions.WithDefaultDurationMonths(cfg.DefaultDurationMonths))
+	}
+	if cfg.RejectPastStart {
+		serviceOpts = append(serviceOpts, subscriptions.WithRejectPastStart())
+	}
+	if cfg.CostDeduplication {
+		serviceOpts = append(serviceOpts, subscriptions.WithCostDeduplication())
+	}
+	if cfg.StatsRefreshInterval > 0 {
+		serviceOpts = append(serviceOpts, subscriptions.WithStatsCache())
+	}
+	if cfg.ServiceNamePattern != "" {
+		serviceOpts = append(serviceOpts, subscriptions.WithServiceNamePattern(regexp.MustCompile(cfg.ServiceNamePattern)))
+	}
+
+	repo := subscriptions.NewRepository(db, log, repoOpts...)
+	service := subscriptions.NewService(repo, log, serviceOpts...)
+	costLimiter := middleware.ConcurrencyLimiter(cfg.CostMaxConcurrency, log)
+	loadShedder := middleware.LoadShedder(cfg.LoadShedWaitThreshold, middleware.PoolWaitTime(db), log)
+	handlerOpts := []subscriptions.HandlerOption{
+		subscriptions.WithDisabledEndpoints(cfg.DisabledEndpoints...),
+		subscriptions.WithStrictDelete(cfg.StrictDelete),
+		subscriptions.WithEndpointTimeouts(cfg.RequestTimeout, cfg.EndpointTimeouts),
+	}
+	// Listings and cost aggregations hold a database connection the longest,
+	// so they are shed first under pool pressure.
+	for _, name := range []string{"list", "services", "subscribers", "users", "diversity", "export"} {
+		handlerOpts = append(handlerOpts, subscriptions.WithEndpointMiddleware(name, loadShedder))
+	}
+	for _, name := range []string{"cost", "cost-rolling", "cost-query", "cost-preview"} {
+		handlerOpts = append(handlerOpts, subscriptions.WithEndpointMiddleware(name, loadShedder, costLimiter))
+	}
+	if cfg.AdminAPIKey != "" {
+		handlerOpts = append(handlerOpts, subscriptions.WithAdminAuth(middleware.APIKey(middleware.APIKeyHeader, cfg.AdminAPIKey, cfg.ExemptRoutes, log)))
+	}
+	handler := subscriptions.NewHandler(service, log, handlerOpts...)
 
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	r.Use(middleware.TrustedProxies(cfg.TrustedProxies))
+	r.Use(chimiddleware.Logger)
+	r.Use(chimiddleware.Recoverer)
+	r.Use(middleware.CORS(middleware.CORSConfig{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		MaxAge:           cfg.CORS.MaxAge,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		Exempt:           cfg.ExemptRoutes,
+	}, log))
+	r.Use(middleware.BodyLogger(log, cfg.LogLevel, cfg.BodyLogMaxBytes))
+
+	spec := apiSpec()
+	specChecks, err := specMiddlewares(spec, log)
+	if err != nil {
+		log.Fatal("Failed to load API spec", map[string]any{"error": err})
+	}
+	if spec == nil {
+		log.Warn("Built without API docs: request validation, duplicate query parameter checks and content negotiation are off", nil)
+	}
+	r.Use(specChecks...)
+	r.Use(middleware.PrettyJSON(log))
+	r.Use(middleware.FieldCase(log))
 
 	// Routes
 	handler.RegisterRoutes(r)
+	if err := handler.CheckEndpointNames(); err != nil {
+		log.Fatal("Invalid endpoint configuration", map[string]any{"error": err})
+	}
+	debug.NewHandler(cfg, log).RegisterRoutes(r)
+	health.NewHandler(log, healthChecks...).RegisterRoutes(r)
+	title, version := apiInfo()
+	links := map[string]string{
+		"health":  "/healthz/detailed",
+		"metrics": "/metrics",
+	}
+	if spec != nil {
+		links["swagger"] = "/v1/swagger/index.html"
+	}
+	index.NewHandler(index.Index{Name: title, Version: version, Links: links}, log).RegisterRoutes(r)
+
+	// Metrics endpoint
+	r.Handle("/metrics", promhttp.Handler())
 
 	// Swagger endpoint
-	r.Route("/v1/swagger", func(r chi.Router) {
-		r.Handle("/*", httpSwagger.Handler())
-	})
+	mountSwagger(r)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	if replicaMonitor != nil {
+		wg.Go(func() { replicaMonitor.Run(ctx) })
+	}
+	if cfg.ReminderInterval > 0 {
+		scheduler := reminders.NewScheduler(service, reminders.NewLogNotifier(log), log, cfg.ReminderInterval, cfg.ReminderWindow,
+			reminders.WithDrainTimeout(shutdownTimeout),
+		)
+		wg.Go(func() { scheduler.Run(ctx) })
+	}
+	if cfg.StatsRefreshInterval > 0 {
+		refresher := subscriptions.NewStatsRefresher(service, log, cfg.StatsRefreshInterval)
+		wg.Go(func() { refresher.Run(ctx) })
+	}
+	if cfg.SummaryRefreshInterval > 0 {
+		refresher := subscriptions.NewSummaryRefresher(service, log, cfg.SummaryRefreshInterval)
+		wg.Go(func() { refresher.Run(ctx) })
+	}
+
+	server := &http.Server{Addr: ":" + cfg.ServerPort, Handler: r}
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
 
-	log.Info("Server starting", map[string]any{"port": port})
-	if err := http.ListenAndServe(":"+port, r); err != nil {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error("Server shutdown error", map[string]any{"error": err})
+		}
+	}()
+
+	log.Info("Server starting", map[string]any{"port": cfg.ServerPort})
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal("Server error", map[string]any{"error": err})
 	}
+
+	wg.Wait()
+	log.Info("Server stopped", nil)
+}
+
+// loggerOptions translates the logging settings of cfg into logger options.
+func loggerOptions(cfg config.Config) []logger.Option {
+	var opts []logger.Option
+	if cfg.LogTZ == "utc" {
+		opts = append(opts, logger.WithUTC())
+	}
+	return opts
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/n-korel/user-subscriptions-api/internal/config"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+	"github.com/n-korel/user-subscriptions-api/internal/subscriptions"
+)
+
+// reportOptions are the filters of a cost report, matching the query
+// parameters of GET /subscriptions/cost.
+type reportOptions struct {
+	StartDate   string
+	EndDate     string
+	UserID      *uuid.UUID
+	ServiceName *string
+}
+
+// runCommand runs the one-off command name with its args instead of the HTTP
+// server, writing the result to stdout.
+func runCommand(ctx context.Context, name string, args []string, cfg config.Config, stdout io.Writer) error {
+	switch name {
+	case "report":
+		opts, err := parseReportArgs(args)
+		if err != nil {
+			return err
+		}
+		return runReport(ctx, cfg, opts, stdout)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// parseReportArgs parses the flags of the report command:
+//
+//	server report --start=01-2025 [--end=12-2025] [--user=<uuid>] [--service=<name>]
+func parseReportArgs(args []string) (reportOptions, error) {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var opts reportOptions
+	var userID, serviceName string
+	fs.StringVar(&opts.StartDate, "start", "", "start month, MM-YYYY")
+	fs.StringVar(&opts.EndDate, "end", "", "end month, MM-YYYY")
+	fs.StringVar(&userID, "user", "", "user UUID")
+	fs.StringVar(&serviceName, "service", "", "service name")
+
+	if err := fs.Parse(args); err != nil {
+		return reportOptions{}, err
+	}
+	if fs.NArg() > 0 {
+		return reportOptions{}, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return reportOptions{}, fmt.Errorf("invalid --user: %w", err)
+		}
+		opts.UserID = &id
+	}
+	if serviceName != "" {
+		opts.ServiceName = &serviceName
+	}
+
+	return opts, nil
+}
+
+// runReport computes the cost for opts and prints it to stdout as JSON. Logs
+// go to stderr so that stdout holds the report only.
+func runReport(ctx context.Context, cfg config.Config, opts reportOptions, stdout io.Writer) error {
+	log, err := logger.NewStderr(cfg.LogLevel, loggerOptions(cfg)...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = log.Sync()
+	}()
+
+	db, err := pgxpool.New(ctx, cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	service := subscriptions.NewService(subscriptions.NewRepository(db, log), log, subscriptions.WithMaxCostMonths(cfg.CostMaxMonths))
+	cost, err := service.GetCostByPeriod(ctx, opts.StartDate, opts.EndDate, opts.UserID, opts.ServiceName)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(stdout).Encode(cost)
+}
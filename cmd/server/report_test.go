@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/n-korel/user-subscriptions-api/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReportArgs(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	serviceName := "Netflix"
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected reportOptions
+		wantErr  string
+	}{
+		{name: "No flags", args: nil, expected: reportOptions{}},
+		{name: "Period", args: []string{"--start=01-2025", "--end=12-2025"}, expected: reportOptions{StartDate: "01-2025", EndDate: "12-2025"}},
+		{
+			name:     "All filters",
+			args:     []string{"--user", userID.String(), "--service=Netflix", "--start=01-2025"},
+			expected: reportOptions{StartDate: "01-2025", UserID: &userID, ServiceName: &serviceName},
+		},
+		{name: "Invalid user", args: []string{"--user=abc"}, wantErr: "invalid --user"},
+		{name: "Unknown flag", args: []string{"--month=01"}, wantErr: "flag provided but not defined: -month"},
+		{name: "Extra argument", args: []string{"--start=01-2025", "now"}, wantErr: `unexpected argument "now"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseReportArgs(tt.args)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, opts)
+		})
+	}
+}
+
+func TestRunCommand_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		wantErr string
+	}{
+		{name: "Unknown command", command: "serve", wantErr: `unknown command "serve"`},
+		{name: "Invalid report arguments", command: "report", args: []string{"--user=abc"}, wantErr: "invalid --user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+
+			err := runCommand(context.Background(), tt.command, tt.args, config.Config{}, &stdout)
+
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
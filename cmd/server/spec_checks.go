@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+	"github.com/n-korel/user-subscriptions-api/internal/middleware"
+)
+
+// specMiddlewares builds the middlewares checking requests against spec:
+// request validation, duplicate query parameters and content negotiation, in
+// that order. A nil spec, as in builds without the API docs, yields none.
+func specMiddlewares(spec []byte, log logger.LoggerInterface) ([]func(http.Handler) http.Handler, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	requestValidator, err := middleware.RequestValidator(spec, log)
+	if err != nil {
+		return nil, fmt.Errorf("request validation: %w", err)
+	}
+
+	duplicateQueryParams, err := middleware.DuplicateQueryParams(spec, log)
+	if err != nil {
+		return nil, fmt.Errorf("query parameter checks: %w", err)
+	}
+
+	contentNegotiator, err := middleware.ContentNegotiator(spec, log)
+	if err != nil {
+		return nil, fmt.Errorf("content negotiation: %w", err)
+	}
+
+	return []func(http.Handler) http.Handler{requestValidator, duplicateQueryParams, contentNegotiator}, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// specCheckRequest is a request the spec middlewares reject when the API docs
+// are built in.
+type specCheckRequest struct {
+	name   string
+	method string
+	target string
+	body   string
+	accept string
+}
+
+var specCheckRequests = []specCheckRequest{
+	{name: "Invalid body", method: http.MethodPost, target: "/v1/subscriptions", body: `{"service_name":42,"price":100,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"01-2025"}`},
+	{name: "Duplicate query parameter", method: http.MethodGet, target: "/v1/subscriptions/cost?start_date=01-2025&start_date=02-2025"},
+	{name: "Unacceptable type", method: http.MethodGet, target: "/v1/subscriptions/cost?start_date=01-2025", accept: "text/csv"},
+}
+
+// serveSpecChecked serves req through the spec middlewares main builds from
+// apiSpec, in front of handlers answering 200.
+func serveSpecChecked(t *testing.T, req specCheckRequest) *httptest.ResponseRecorder {
+	t.Helper()
+
+	log, err := logger.New("error")
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	specChecks, err := specMiddlewares(apiSpec(), log)
+	if err != nil {
+		t.Fatalf("failed to build spec middlewares: %v", err)
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	r := chi.NewRouter()
+	r.Use(specChecks...)
+	r.Post("/v1/subscriptions", ok)
+	r.Get("/v1/subscriptions/cost", ok)
+
+	httpReq := httptest.NewRequest(req.method, req.target, strings.NewReader(req.body))
+	if req.body != "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.accept != "" {
+		httpReq.Header.Set("Accept", req.accept)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httpReq)
+
+	return w
+}
@@ -0,0 +1,27 @@
+//go:build !noswagger
+
+package main
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/n-korel/user-subscriptions-api/docs" // swagger docs
+	httpSwagger "github.com/swaggo/http-swagger/v2"
+)
+
+// apiSpec returns the generated Swagger spec. Builds tagged noswagger have
+// none, see swagger_stub.go.
+func apiSpec() []byte {
+	return []byte(docs.SwaggerInfo.ReadDoc())
+}
+
+// apiInfo returns the title and version of the API.
+func apiInfo() (title, version string) {
+	return docs.SwaggerInfo.Title, docs.SwaggerInfo.Version
+}
+
+// mountSwagger serves Swagger UI under /v1/swagger.
+func mountSwagger(r chi.Router) {
+	r.Route("/v1/swagger", func(r chi.Router) {
+		r.Handle("/*", httpSwagger.Handler())
+	})
+}
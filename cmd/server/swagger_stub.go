@@ -0,0 +1,19 @@
+//go:build noswagger
+
+package main
+
+import "github.com/go-chi/chi/v5"
+
+// apiSpec returns nil: the server is built without the generated docs, so the
+// middlewares checking requests against the spec are left out.
+func apiSpec() []byte {
+	return nil
+}
+
+// apiInfo returns the title and version of the @title and @version
+// annotations of main.
+func apiInfo() (title, version string) {
+	return "User Subscriptions API", "1.0"
+}
+
+func mountSwagger(r chi.Router) {}
@@ -0,0 +1,43 @@
+//go:build noswagger
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMountSwagger_WithoutDocs(t *testing.T) {
+	r := chi.NewRouter()
+	mountSwagger(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/swagger/index.html", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Nil(t, apiSpec())
+
+	title, version := apiInfo()
+	assert.Equal(t, "User Subscriptions API", title)
+	assert.Equal(t, "1.0", version)
+}
+
+func TestSpecMiddlewares_WithoutDocs(t *testing.T) {
+	specChecks, err := specMiddlewares(apiSpec(), nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, specChecks)
+
+	for _, req := range specCheckRequests {
+		t.Run(req.name, func(t *testing.T) {
+			w := serveSpecChecked(t, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, "nothing checks requests without the spec")
+		})
+	}
+}
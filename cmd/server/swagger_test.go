@@ -0,0 +1,54 @@
+//go:build !noswagger
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMountSwagger(t *testing.T) {
+	r := chi.NewRouter()
+	mountSwagger(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/swagger/index.html", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var spec map[string]any
+	assert.NoError(t, json.Unmarshal(apiSpec(), &spec))
+	assert.Contains(t, spec["paths"], "/subscriptions")
+
+	title, version := apiInfo()
+	assert.Equal(t, "User Subscriptions API", title)
+	assert.Equal(t, "1.0", version)
+}
+
+func TestSpecMiddlewares(t *testing.T) {
+	want := map[string]int{
+		"Invalid body":              http.StatusBadRequest,
+		"Duplicate query parameter": http.StatusBadRequest,
+		"Unacceptable type":         http.StatusNotAcceptable,
+	}
+
+	for _, req := range specCheckRequests {
+		t.Run(req.name, func(t *testing.T) {
+			w := serveSpecChecked(t, req)
+
+			assert.Equal(t, want[req.name], w.Code)
+		})
+	}
+
+	t.Run("Valid request", func(t *testing.T) {
+		w := serveSpecChecked(t, specCheckRequest{method: http.MethodGet, target: "/v1/subscriptions/cost?start_date=01-2025"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
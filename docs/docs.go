@@ -24,9 +24,96 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/recompute-summaries": {
+            "post": {
+                "description": "Recompute the per-user cost summaries served by GET /subscriptions/summary from the subscriptions active in the current month",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Recompute user cost summaries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.RecomputeSummariesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/debug/config": {
+            "get": {
+                "description": "Retrieve the effective service configuration with secrets redacted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "debug"
+                ],
+                "summary": "Get effective configuration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Debug API key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/config.Config"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/subscriptions": {
             "get": {
-                "description": "Retrieve all subscriptions",
+                "description": "Retrieve all subscriptions, newest first unless sort_by is given. With external_id only the subscription with that external ID is listed. Listings over the hard cap are cut short and flagged with truncated.",
                 "produces": [
                     "application/json"
                 ],
@@ -34,12 +121,51 @@ const docTemplate = `{
                     "subscriptions"
                 ],
                 "summary": "Get all subscriptions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "id",
+                            "service_name",
+                            "price",
+                            "start_date",
+                            "end_date",
+                            "created_at",
+                            "updated_at"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "External ID",
+                        "name": "external_id",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/subscriptions.Response"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
                     }
                 }
             },
@@ -64,6 +190,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/subscriptions.CreateSubscriptionRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "* to create only if no subscription has the external_id, answering 412 otherwise",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -71,6 +203,160 @@ const docTemplate = `{
                         "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/subscriptions.Response"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "external_id is already used",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "external_id is already used and If-None-Match is *",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "start_date is before the current month in strict mode",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            },
+            "options": {
+                "description": "List the supported operations and their parameters. The Allow header lists the methods.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Describe the subscriptions collection",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/subscriptions.OperationDescription"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Allow": {
+                                "type": "string",
+                                "description": "Supported methods"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/budget-status": {
+            "get": {
+                "description": "Compare the current monthly cost of every budgeted service with its monthly cap, reporting the services over budget and by how much",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get budget status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/subscriptions.BudgetStatus"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/changes": {
+            "get": {
+                "description": "Retrieve subscriptions created or updated after since, oldest change first, for incremental sync. Deletions are not reported.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscriptions changed since a time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cutoff time (RFC 3339), exclusive",
+                        "name": "since",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/subscriptions.Subscription"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -78,6 +364,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/subscriptions.Response"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
                     }
                 }
             }
@@ -95,17 +387,22 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Start date (MM-YYYY format)",
+                        "description": "Start date (MM-YYYY format or now for the current month), required unless year is set",
                         "name": "start_date",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "End date (MM-YYYY format)",
+                        "description": "End date (MM-YYYY format or now for the current month)",
                         "name": "end_date",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Whole year (YYYY), shorthand for start_date=01-YYYY and end_date=12-YYYY",
+                        "name": "year",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "User ID (UUID)",
@@ -117,6 +414,24 @@ const docTemplate = `{
                         "description": "Service name",
                         "name": "service_name",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List the IDs of the included subscriptions in subscription_ids",
+                        "name": "include_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response: return 304 if matching subscriptions have not changed since",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return 304 if no matching subscription was created or updated since this time; ignored with If-None-Match",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -126,6 +441,9 @@ const docTemplate = `{
                             "$ref": "#/definitions/subscriptions.Response"
                         }
                     },
+                    "304": {
+                        "description": "Not Modified"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -135,42 +453,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/{id}": {
-            "delete": {
-                "description": "Delete an existing subscription",
+        "/subscriptions/cost/preview": {
+            "post": {
+                "description": "Calculate total cost of subscriptions like POST /subscriptions/cost/query, and the total if the subscriptions to a service among them had another price. Nothing is changed.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "subscriptions"
                 ],
-                "summary": "Delete a subscription",
+                "summary": "Preview the cost of a price change",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Cost filter and price change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.CostPreviewRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/subscriptions.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.CostPreviewResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/subscriptions.Response"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update an existing subscription",
+            }
+        },
+        "/subscriptions/cost/query": {
+            "post": {
+                "description": "Calculate total cost of subscriptions like GET /subscriptions/cost, taking the filter as a JSON body with lists of users and services that do not fit a query string. Empty lists are not applied.",
                 "consumes": [
                     "application/json"
                 ],
@@ -180,30 +517,35 @@ const docTemplate = `{
                 "tags": [
                     "subscriptions"
                 ],
-                "summary": "Update a subscription",
+                "summary": "Get subscriptions cost for a filter body",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Subscription data",
+                        "description": "Cost filter",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/subscriptions.UpdateSubscriptionRequest"
+                            "$ref": "#/definitions/subscriptions.CostQuery"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "subscription_ids only with include_ids",
                         "schema": {
-                            "$ref": "#/definitions/subscriptions.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.CostWithIDsResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -211,27 +553,1205 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/subscriptions.Response"
                         }
+                    }
+                }
+            }
+        },
+        "/subscriptions/cost/rolling": {
+            "get": {
+                "description": "Calculate total cost of subscriptions for a window of N months ending with the current month",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscriptions cost for the last months",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Window size in months (1-120)",
+                        "name": "months",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.CostResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/subscriptions.Response"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "subscriptions.CreateSubscriptionRequest": {
-            "type": "object",
-            "properties": {
-                "end_date": {
-                    "type": "string"
-                },
-                "price": {
-                    "type": "integer"
-                },
+        },
+        "/subscriptions/date-range": {
+            "get": {
+                "description": "Retrieve the earliest start date and the latest end date across subscriptions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription date bounds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.DateRangeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/diversity": {
+            "get": {
+                "description": "Retrieve a paginated list of distinct users with the number of distinct services they subscribe to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get distinct services per user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.Page-subscriptions_UserServices"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/export": {
+            "get": {
+                "description": "Export subscriptions as CSV, JSON Lines or an aligned text table, filtered like the cost endpoint",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "text/plain"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Export subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export format: csv (default), jsonl or table",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (MM-YYYY format)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (MM-YYYY format)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/meta": {
+            "get": {
+                "description": "Retrieve the values the server accepts under its current configuration: date format, service name pattern, sort fields and orders, export formats and limits.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get accepted values",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.MetaResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/services": {
+            "get": {
+                "description": "Retrieve a paginated list of distinct service names, optionally filtered by prefix or searched by q. Search results are ranked exact match first, then prefix matches, then substring matches.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get distinct services",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name prefix (case-insensitive)",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text contained in the service name (case-insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.Page-string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/stats": {
+            "get": {
+                "description": "Aggregates over all subscriptions. When periodic refresh is enabled the latest snapshot is served; computed_at tells its age.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription stats",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Recompute instead of serving the cached snapshot",
+                        "name": "fresh",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.StatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/subscribers": {
+            "get": {
+                "description": "Retrieve a paginated list of distinct users with an active subscription to the service",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscribers of a service",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.Page-string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/summary": {
+            "get": {
+                "description": "Serve the monthly cost and number of active subscriptions of a user from the precomputed summaries. They are as old as computed_at, see POST /admin/recompute-summaries; use the cost endpoint for an exact figure.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get a user's cost summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.UserCostSummary"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "No active subscriptions at the last recompute",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/users": {
+            "get": {
+                "description": "Retrieve a paginated list of distinct users with the number of their subscriptions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get users with subscriptions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.Page-subscriptions_UserSubscriptions"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/validate": {
+            "post": {
+                "description": "Check a subscription payload as create would, without saving it. Every violation is reported.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Validate a subscription payload",
+                "parameters": [
+                    {
+                        "description": "Subscription data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.CreateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.ValidationResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "description": "Retrieve a subscription by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.Subscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a subscription. Deleting a missing subscription also succeeds, unless strict delete mode is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Delete a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Strict delete mode only",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update an existing subscription. The owner (user_id) cannot be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Update a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.UpdateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "external_id is already used",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/renew": {
+            "post": {
+                "description": "Create a new subscription for a new period, copying service, price and user from the original and linking it via renewed_from_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Renew a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Renewal period",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.RenewSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.Subscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/subscriptions": {
+            "delete": {
+                "description": "Permanently delete every subscription of a user (data erasure request)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete all subscriptions of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.DeleteUserSubscriptionsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/users/{user_id}/subscriptions/cancel": {
+            "post": {
+                "description": "End every subscription of a user active in the current month with the current month, e.g. when the account is closed. Subscriptions already ending by then or starting later are left untouched.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Cancel all subscriptions of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/subscriptions.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/subscriptions.CancelUserSubscriptionsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/subscriptions.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminAPIKey": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "config.CORSConfig": {
+            "type": "object",
+            "properties": {
+                "allow_credentials": {
+                    "type": "boolean"
+                },
+                "allowed_origins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max_age": {
+                    "type": "integer"
+                }
+            }
+        },
+        "config.Config": {
+            "type": "object",
+            "properties": {
+                "admin_api_key": {
+                    "type": "string"
+                },
+                "body_log_max_bytes": {
+                    "type": "integer"
+                },
+                "cors": {
+                    "$ref": "#/definitions/config.CORSConfig"
+                },
+                "cost_deduplication": {
+                    "type": "boolean"
+                },
+                "cost_max_concurrency": {
+                    "type": "integer"
+                },
+                "cost_max_months": {
+                    "type": "integer"
+                },
+                "debug_api_key": {
+                    "type": "string"
+                },
+                "default_duration_months": {
+                    "type": "integer"
+                },
+                "disabled_endpoints": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "dsn": {
+                    "type": "string"
+                },
+                "endpoint_timeouts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "exempt_routes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "getall_hard_cap": {
+                    "type": "integer"
+                },
+                "load_shed_wait_threshold": {
+                    "type": "integer"
+                },
+                "log_level": {
+                    "type": "string"
+                },
+                "log_tz": {
+                    "type": "string"
+                },
+                "read_dsn": {
+                    "type": "string"
+                },
+                "reject_past_start": {
+                    "type": "boolean"
+                },
+                "reminder_interval": {
+                    "type": "integer"
+                },
+                "reminder_window": {
+                    "type": "integer"
+                },
+                "request_timeout": {
+                    "type": "integer"
+                },
+                "server_port": {
+                    "type": "string"
+                },
+                "service_name_pattern": {
+                    "type": "string"
+                },
+                "stats_refresh_interval": {
+                    "type": "integer"
+                },
+                "strict_delete": {
+                    "type": "boolean"
+                },
+                "summary_refresh_interval": {
+                    "type": "integer"
+                },
+                "trusted_proxies": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "subscriptions.BudgetStatus": {
+            "type": "object",
+            "properties": {
+                "monthly_cap": {
+                    "type": "integer"
+                },
+                "monthly_cost": {
+                    "type": "integer"
+                },
+                "over_budget": {
+                    "type": "boolean"
+                },
+                "overage": {
+                    "type": "integer"
+                },
+                "service_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscriptions.CancelUserSubscriptionsResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.CostPreviewRequest": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "$ref": "#/definitions/subscriptions.PriceChange"
+                },
+                "filter": {
+                    "$ref": "#/definitions/subscriptions.CostQuery"
+                }
+            }
+        },
+        "subscriptions.CostPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "delta": {
+                    "type": "integer"
+                },
+                "projected_cost": {
+                    "type": "integer"
+                },
+                "total_cost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.CostQuery": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string"
+                },
+                "include_ids": {
+                    "type": "boolean"
+                },
+                "service_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "subscriptions.CostResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "total_cost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.CostWithIDsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "subscription_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "total_cost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.CreateSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "external_id": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "price": {
+                    "type": "integer",
+                    "x-numeric-string": true
+                },
                 "service_name": {
                     "type": "string"
                 },
@@ -243,27 +1763,281 @@ const docTemplate = `{
                 }
             }
         },
+        "subscriptions.DateRangeResponse": {
+            "type": "object",
+            "properties": {
+                "max_end_date": {
+                    "type": "string"
+                },
+                "min_start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscriptions.DeleteUserSubscriptionsResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.MetaResponse": {
+            "type": "object",
+            "properties": {
+                "date_format": {
+                    "type": "string"
+                },
+                "export_formats": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max_cost_months": {
+                    "type": "integer"
+                },
+                "max_page_limit": {
+                    "type": "integer"
+                },
+                "service_name_pattern": {
+                    "type": "string"
+                },
+                "sort_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sort_orders": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "subscriptions.OperationDescription": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "subscriptions.Page-string": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.Page-subscriptions_UserServices": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/subscriptions.UserServices"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.Page-subscriptions_UserSubscriptions": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/subscriptions.UserSubscriptions"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.PriceChange": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "integer"
+                },
+                "service_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscriptions.RecomputeSummariesResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.RenewSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
         "subscriptions.Response": {
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string"
+                },
                 "data": {},
                 "error": {
                     "type": "string"
                 },
                 "status": {
                     "type": "string"
+                },
+                "truncated": {
+                    "type": "boolean"
+                },
+                "warning": {
+                    "type": "string"
                 }
             }
         },
-        "subscriptions.UpdateSubscriptionRequest": {
+        "subscriptions.StatsResponse": {
+            "type": "object",
+            "properties": {
+                "active_subscriptions": {
+                    "type": "integer"
+                },
+                "computed_at": {
+                    "type": "string"
+                },
+                "monthly_cost": {
+                    "type": "integer"
+                },
+                "services": {
+                    "type": "integer"
+                },
+                "subscriptions": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "subscriptions.Subscription": {
             "type": "object",
             "properties": {
+                "active": {
+                    "description": "Active and NextBillingDate are computed by the service and not stored,\nsee isActive and nextBillingDate.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
                 "end_date": {
                     "type": "string"
                 },
+                "external_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "next_billing_date": {
+                    "type": "string"
+                },
                 "price": {
                     "type": "integer"
                 },
+                "reference_code": {
+                    "type": "string"
+                },
+                "renewed_from_id": {
+                    "type": "integer"
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscriptions.UpdateSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "external_id": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "price": {
+                    "type": "integer",
+                    "x-numeric-string": true
+                },
                 "service_name": {
                     "type": "string"
                 },
@@ -274,6 +2048,77 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "subscriptions.UserCostSummary": {
+            "type": "object",
+            "properties": {
+                "active_subscriptions": {
+                    "type": "integer"
+                },
+                "computed_at": {
+                    "type": "string"
+                },
+                "monthly_cost": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscriptions.UserServices": {
+            "type": "object",
+            "properties": {
+                "distinct_services": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscriptions.UserSubscriptions": {
+            "type": "object",
+            "properties": {
+                "subscriptions": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscriptions.ValidationError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscriptions.ValidationResponse": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/subscriptions.ValidationError"
+                    }
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminAPIKey": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
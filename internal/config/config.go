@@ -0,0 +1,338 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "***"
+
+type Config struct {
+	DSN                    string                   `json:"dsn"`
+	ReadDSN                string                   `json:"read_dsn"`
+	ServerPort             string                   `json:"server_port"`
+	LogLevel               string                   `json:"log_level"`
+	LogTZ                  string                   `json:"log_tz"`
+	CORS                   CORSConfig               `json:"cors"`
+	TrustedProxies         []netip.Prefix           `json:"trusted_proxies" swaggertype:"array,string"`
+	BodyLogMaxBytes        int                      `json:"body_log_max_bytes"`
+	DefaultDurationMonths  int                      `json:"default_duration_months"`
+	DebugAPIKey            string                   `json:"debug_api_key"`
+	AdminAPIKey            string                   `json:"admin_api_key"`
+	LoadShedWaitThreshold  time.Duration            `json:"load_shed_wait_threshold" swaggertype:"integer"`
+	DisabledEndpoints      []string                 `json:"disabled_endpoints"`
+	ExemptRoutes           []string                 `json:"exempt_routes"`
+	ReminderInterval       time.Duration            `json:"reminder_interval" swaggertype:"integer"`
+	ReminderWindow         time.Duration            `json:"reminder_window" swaggertype:"integer"`
+	CostMaxConcurrency     int                      `json:"cost_max_concurrency"`
+	CostMaxMonths          int                      `json:"cost_max_months"`
+	GetAllHardCap          int                      `json:"getall_hard_cap"`
+	CostDeduplication      bool                     `json:"cost_deduplication"`
+	StrictDelete           bool                     `json:"strict_delete"`
+	RejectPastStart        bool                     `json:"reject_past_start"`
+	StatsRefreshInterval   time.Duration            `json:"stats_refresh_interval" swaggertype:"integer"`
+	SummaryRefreshInterval time.Duration            `json:"summary_refresh_interval" swaggertype:"integer"`
+	ServiceNamePattern     string                   `json:"service_name_pattern"`
+	RequestTimeout         time.Duration            `json:"request_timeout" swaggertype:"integer"`
+	EndpointTimeouts       map[string]time.Duration `json:"endpoint_timeouts" swaggertype:"object,integer"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	MaxAge           int      `json:"max_age"`
+	AllowCredentials bool     `json:"allow_credentials"`
+}
+
+// Load reads the configuration from environment variables, applying defaults
+// for optional settings.
+func Load() (Config, error) {
+	cfg := Config{
+		DSN:                os.Getenv("DSN"),
+		ReadDSN:            getEnv("READ_DSN", os.Getenv("DSN_READONLY")),
+		ServerPort:         getEnv("SERVER_PORT", "8080"),
+		LogLevel:           getEnv("LOG_LEVEL", "info"),
+		LogTZ:              getEnv("LOG_TZ", "local"),
+		CORS:               CORSConfig{AllowedOrigins: []string{"*"}},
+		BodyLogMaxBytes:    1024,
+		DebugAPIKey:        os.Getenv("DEBUG_API_KEY"),
+		AdminAPIKey:        os.Getenv("ADMIN_API_KEY"),
+		ServiceNamePattern: os.Getenv("SERVICE_NAME_PATTERN"),
+	}
+
+	if cfg.DSN == "" {
+		return Config{}, fmt.Errorf("DSN environment variable is not set")
+	}
+
+	if cfg.LogTZ != "local" && cfg.LogTZ != "utc" {
+		return Config{}, fmt.Errorf("invalid LOG_TZ %q: must be utc or local", cfg.LogTZ)
+	}
+
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		cfg.CORS.AllowedOrigins = strings.Split(origins, ",")
+	}
+
+	var err error
+	if cfg.TrustedProxies, err = getEnvPrefixes("TRUSTED_PROXIES"); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.CORS.MaxAge, err = getEnvInt("CORS_MAX_AGE", 0); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.CORS.AllowCredentials, err = getEnvBool("CORS_ALLOW_CREDENTIALS", false); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.BodyLogMaxBytes, err = getEnvInt("BODY_LOG_MAX_BYTES", cfg.BodyLogMaxBytes); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.DefaultDurationMonths, err = getEnvInt("DEFAULT_DURATION_MONTHS", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.DefaultDurationMonths < 0 {
+		return Config{}, fmt.Errorf("DEFAULT_DURATION_MONTHS must not be negative")
+	}
+
+	if cfg.LoadShedWaitThreshold, err = getEnvDuration("LOAD_SHED_WAIT_THRESHOLD", 0); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.StrictDelete, err = getEnvBool("STRICT_DELETE", false); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.RejectPastStart, err = getEnvBool("REJECT_PAST_START", false); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.CostMaxConcurrency, err = getEnvInt("COST_MAX_CONCURRENCY", 0); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.CostMaxMonths, err = getEnvInt("COST_MAX_MONTHS", 120); err != nil {
+		return Config{}, err
+	}
+	if cfg.CostMaxMonths < 0 {
+		return Config{}, fmt.Errorf("COST_MAX_MONTHS must not be negative")
+	}
+
+	if cfg.GetAllHardCap, err = getEnvInt("GETALL_HARD_CAP", 10000); err != nil {
+		return Config{}, err
+	}
+	if cfg.GetAllHardCap < 0 {
+		return Config{}, fmt.Errorf("GETALL_HARD_CAP must not be negative")
+	}
+
+	if cfg.CostDeduplication, err = getEnvBool("COST_DEDUPLICATION", false); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.ReminderInterval, err = getEnvDuration("REMINDER_INTERVAL", 0); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.ReminderWindow, err = getEnvDuration("REMINDER_WINDOW", 7*24*time.Hour); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.StatsRefreshInterval, err = getEnvDuration("STATS_REFRESH_INTERVAL", 0); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.SummaryRefreshInterval, err = getEnvDuration("SUMMARY_REFRESH_INTERVAL", 0); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.RequestTimeout, err = getEnvDuration("REQUEST_TIMEOUT", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+
+	// Exports read and render every matching subscription.
+	if cfg.EndpointTimeouts, err = getEnvDurations("ENDPOINT_TIMEOUTS", map[string]time.Duration{"export": 2 * time.Minute}); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.ServiceNamePattern != "" {
+		if _, err := regexp.Compile(cfg.ServiceNamePattern); err != nil {
+			return Config{}, fmt.Errorf("invalid SERVICE_NAME_PATTERN: %w", err)
+		}
+	}
+
+	if disabled := os.Getenv("DISABLED_ENDPOINTS"); disabled != "" {
+		for _, name := range strings.Split(disabled, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.DisabledEndpoints = append(cfg.DisabledEndpoints, name)
+			}
+		}
+	}
+
+	if exempt := os.Getenv("EXEMPT_ROUTES"); exempt != "" {
+		for _, pattern := range strings.Split(exempt, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				cfg.ExemptRoutes = append(cfg.ExemptRoutes, pattern)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// Redacted returns a copy of the configuration safe to expose: the DSN password
+// and API keys are replaced with a placeholder.
+func (c Config) Redacted() Config {
+	redactedCfg := c
+	redactedCfg.CORS.AllowedOrigins = append([]string(nil), c.CORS.AllowedOrigins...)
+	redactedCfg.DisabledEndpoints = append([]string(nil), c.DisabledEndpoints...)
+	redactedCfg.ExemptRoutes = append([]string(nil), c.ExemptRoutes...)
+	redactedCfg.TrustedProxies = append([]netip.Prefix(nil), c.TrustedProxies...)
+	redactedCfg.EndpointTimeouts = maps.Clone(c.EndpointTimeouts)
+
+	redactedCfg.DSN = redactDSN(c.DSN)
+	if c.ReadDSN != "" {
+		redactedCfg.ReadDSN = redactDSN(c.ReadDSN)
+	}
+
+	if c.DebugAPIKey != "" {
+		redactedCfg.DebugAPIKey = redacted
+	}
+	if c.AdminAPIKey != "" {
+		redactedCfg.AdminAPIKey = redacted
+	}
+
+	return redactedCfg
+}
+
+// redactDSN hides the passwords of a connection URL, both in the userinfo and
+// in the password query parameters. Any other form, such as a keyword DSN
+// "host=... password=...", is replaced whole: its quoting rules are not worth
+// parsing just to keep the host readable in the logs.
+func redactDSN(dsn string) string {
+	parsed, err := url.Parse(dsn)
+	if err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+		return redacted
+	}
+
+	query := parsed.Query()
+	masked := false
+	for _, key := range []string{"password", "sslpassword"} {
+		if query.Has(key) {
+			query.Set(key, "xxxxx")
+			masked = true
+		}
+	}
+	if masked {
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.Redacted()
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// getEnvDurations parses a comma-separated list of name=duration pairs, such as
+// export=5m,cost=10s, over a copy of defaults.
+func getEnvDurations(key string, defaults map[string]time.Duration) (map[string]time.Duration, error) {
+	durations := maps.Clone(defaults)
+
+	value := os.Getenv(key)
+	if value == "" {
+		return durations, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		name, rawDuration, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s: %q is not name=duration", key, pair)
+		}
+
+		parsed, err := time.ParseDuration(strings.TrimSpace(rawDuration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		durations[strings.TrimSpace(name)] = parsed
+	}
+	return durations, nil
+}
+
+// getEnvPrefixes parses a comma-separated list of CIDR prefixes, such as
+// 10.0.0.0/8,192.168.1.1. A bare address stands for a prefix of that address only.
+func getEnvPrefixes(key string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,57 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/n-korel/user-subscriptions-api/internal/config"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+	"github.com/n-korel/user-subscriptions-api/internal/middleware"
+)
+
+type Handler struct {
+	cfg config.Config
+	log logger.LoggerInterface
+}
+
+func NewHandler(cfg config.Config, log logger.LoggerInterface) *Handler {
+	return &Handler{cfg: cfg, log: log}
+}
+
+// RegisterRoutes mounts the debug endpoints. They are only available when
+// DEBUG_API_KEY is configured, and require it unless exempted by EXEMPT_ROUTES.
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	if h.cfg.DebugAPIKey == "" {
+		return
+	}
+
+	r.Route("/v1/debug", func(r chi.Router) {
+		r.Use(middleware.APIKey(middleware.APIKeyHeader, h.cfg.DebugAPIKey, h.cfg.ExemptRoutes, h.log))
+		r.Get("/config", h.GetConfig)
+	})
+}
+
+// GetConfig godoc
+//
+//	@Summary		Get effective configuration
+//	@Description	Retrieve the effective service configuration with secrets redacted
+//	@Tags			debug
+//	@Produce		json
+//	@Param			X-API-Key	header		string	true	"Debug API key"
+//	@Success		200			{object}	config.Config
+//	@Failure		401			{object}	map[string]string
+//	@Router			/debug/config [get]
+func (h *Handler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /debug/config", nil)
+	writeJSON(w, http.StatusOK, map[string]any{"status": "success", "data": h.cfg.Redacted()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// Status is the state of a subsystem or of the service as a whole.
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// checkTimeout bounds a single check; a check that does not finish in time is down.
+const checkTimeout = 2 * time.Second
+
+// Check probes one subsystem. A subsystem is down when Run fails and degraded
+// when Run takes longer than SlowAfter. The service is unavailable while a
+// Critical subsystem is down.
+type Check struct {
+	Name      string
+	Critical  bool
+	SlowAfter time.Duration
+	Run       func(ctx context.Context) error
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Status    Status  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Summary is the body of the detailed health check.
+type Summary struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type Handler struct {
+	checks []Check
+	log    logger.LoggerInterface
+}
+
+func NewHandler(log logger.LoggerInterface, checks ...Check) *Handler {
+	return &Handler{checks: checks, log: log}
+}
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/healthz/detailed", h.GetDetailed)
+}
+
+// GetDetailed runs all checks concurrently and reports each subsystem. It
+// answers 200 while every critical subsystem is up, possibly degraded, and 503
+// otherwise.
+func (h *Handler) GetDetailed(w http.ResponseWriter, r *http.Request) {
+	summary := h.run(r.Context())
+
+	status := http.StatusOK
+	if summary.Status == StatusDown {
+		h.log.Warn("Health check failed", map[string]any{"checks": summary.Checks})
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, map[string]any{"status": responseStatus(status), "data": summary})
+}
+
+func (h *Handler) run(ctx context.Context) Summary {
+	results := make([]Result, len(h.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Go(func() {
+			results[i] = runCheck(ctx, check, checkTimeout)
+		})
+	}
+	wg.Wait()
+
+	summary := Summary{Status: StatusOK, Checks: make(map[string]Result, len(h.checks))}
+	for i, check := range h.checks {
+		result := results[i]
+		summary.Checks[check.Name] = result
+
+		switch {
+		case result.Status == StatusDown && check.Critical:
+			summary.Status = StatusDown
+		case result.Status != StatusOK && summary.Status == StatusOK:
+			summary.Status = StatusDegraded
+		}
+	}
+	return summary
+}
+
+func runCheck(ctx context.Context, check Check, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	latency := time.Since(start)
+
+	result := Result{Status: StatusOK, LatencyMS: float64(latency.Microseconds()) / 1000}
+	switch {
+	case err != nil:
+		result.Status = StatusDown
+		result.Error = err.Error()
+	case check.SlowAfter > 0 && latency > check.SlowAfter:
+		result.Status = StatusDegraded
+	}
+	return result
+}
+
+func responseStatus(status int) string {
+	if status == http.StatusOK {
+		return "success"
+	}
+	return "error"
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,130 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, fields map[string]any)  {}
+func (m *MockLogger) Error(message string, fields map[string]any) {}
+func (m *MockLogger) Warn(message string, fields map[string]any)  {}
+func (m *MockLogger) Debug(message string, fields map[string]any) {}
+func (m *MockLogger) Fatal(message string, fields map[string]any) {}
+func (m *MockLogger) Sync() error                                 { return nil }
+
+func healthy(ctx context.Context) error { return nil }
+
+func failing(ctx context.Context) error { return errors.New("connection refused") }
+
+func slow(ctx context.Context) error {
+	time.Sleep(5 * time.Millisecond)
+	return nil
+}
+
+func TestGetDetailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []Check
+		status   int
+		overall  Status
+		statuses map[string]Status
+	}{
+		{
+			name: "All healthy",
+			checks: []Check{
+				{Name: "database", Critical: true, Run: healthy},
+				{Name: "cache", Run: healthy},
+			},
+			status:   http.StatusOK,
+			overall:  StatusOK,
+			statuses: map[string]Status{"database": StatusOK, "cache": StatusOK},
+		},
+		{
+			name: "Non-critical subsystem down",
+			checks: []Check{
+				{Name: "database", Critical: true, Run: healthy},
+				{Name: "webhook", Run: failing},
+			},
+			status:   http.StatusOK,
+			overall:  StatusDegraded,
+			statuses: map[string]Status{"database": StatusOK, "webhook": StatusDown},
+		},
+		{
+			name: "Critical subsystem slow",
+			checks: []Check{
+				{Name: "database", Critical: true, SlowAfter: time.Millisecond, Run: slow},
+			},
+			status:   http.StatusOK,
+			overall:  StatusDegraded,
+			statuses: map[string]Status{"database": StatusDegraded},
+		},
+		{
+			name: "Critical subsystem down",
+			checks: []Check{
+				{Name: "database", Critical: true, Run: failing},
+				{Name: "cache", Run: healthy},
+				{Name: "webhook", Run: failing},
+			},
+			status:   http.StatusServiceUnavailable,
+			overall:  StatusDown,
+			statuses: map[string]Status{"database": StatusDown, "cache": StatusOK, "webhook": StatusDown},
+		},
+		{
+			name:     "No checks",
+			status:   http.StatusOK,
+			overall:  StatusOK,
+			statuses: map[string]Status{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			NewHandler(&MockLogger{}, tt.checks...).RegisterRoutes(r)
+
+			req := httptest.NewRequest(http.MethodGet, "/healthz/detailed", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+
+			var response struct {
+				Data Summary `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			assert.Equal(t, tt.overall, response.Data.Status)
+			statuses := make(map[string]Status)
+			for name, result := range response.Data.Checks {
+				statuses[name] = result.Status
+				if result.Status == StatusDown {
+					assert.Equal(t, "connection refused", result.Error)
+				}
+			}
+			assert.Equal(t, tt.statuses, statuses)
+		})
+	}
+}
+
+func TestRunCheck_Timeout(t *testing.T) {
+	result := runCheck(context.Background(), Check{Name: "database", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}, 10*time.Millisecond)
+
+	assert.Equal(t, StatusDown, result.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), result.Error)
+	assert.GreaterOrEqual(t, result.LatencyMS, float64(10))
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// Monitor periodically runs a check and remembers whether the last run
+// succeeded, so callers can route around an unhealthy dependency.
+type Monitor struct {
+	name     string
+	check    func(ctx context.Context) error
+	interval time.Duration
+	log      logger.LoggerInterface
+	healthy  atomic.Bool
+}
+
+// NewMonitor returns a monitor that considers the dependency healthy until the
+// first failed check.
+func NewMonitor(name string, check func(ctx context.Context) error, interval time.Duration, log logger.LoggerInterface) *Monitor {
+	m := &Monitor{name: name, check: check, interval: interval, log: log}
+	m.healthy.Store(true)
+	return m
+}
+
+// Healthy reports the result of the last check.
+func (m *Monitor) Healthy() bool {
+	return m.healthy.Load()
+}
+
+// Run checks immediately and then every interval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.probe(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Monitor) probe(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	err := m.check(checkCtx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	healthy := err == nil
+	if m.healthy.Swap(healthy) != healthy {
+		if healthy {
+			m.log.Info("Dependency recovered", map[string]any{"name": m.name})
+		} else {
+			m.log.Warn("Dependency unhealthy", map[string]any{"name": m.name, "error": err})
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitor_Probe(t *testing.T) {
+	var checkErr error
+	monitor := NewMonitor("database_replica", func(ctx context.Context) error { return checkErr }, time.Second, &MockLogger{})
+
+	assert.True(t, monitor.Healthy(), "healthy until the first failed check")
+
+	checkErr = errors.New("connection refused")
+	monitor.probe(context.Background())
+	assert.False(t, monitor.Healthy())
+
+	checkErr = nil
+	monitor.probe(context.Background())
+	assert.True(t, monitor.Healthy())
+}
@@ -0,0 +1,46 @@
+package index
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// Index describes the API to clients landing on the root path. Links maps a
+// name, such as "swagger", to a path on this server.
+type Index struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Links   map[string]string `json:"links"`
+}
+
+type Handler struct {
+	index Index
+	log   logger.LoggerInterface
+}
+
+func NewHandler(index Index, log logger.LoggerInterface) *Handler {
+	return &Handler{index: index, log: log}
+}
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.GetIndex)
+}
+
+// GetIndex answers with the API name, version and links to its documentation
+// and operational endpoints.
+func (h *Handler) GetIndex(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /", nil)
+	writeJSON(w, http.StatusOK, map[string]any{"status": "success", "data": h.index})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,54 @@
+package index
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, fields map[string]any)  {}
+func (m *MockLogger) Error(message string, fields map[string]any) {}
+func (m *MockLogger) Warn(message string, fields map[string]any)  {}
+func (m *MockLogger) Debug(message string, fields map[string]any) {}
+func (m *MockLogger) Fatal(message string, fields map[string]any) {}
+func (m *MockLogger) Sync() error                                 { return nil }
+
+func TestGetIndex(t *testing.T) {
+	handler := NewHandler(Index{
+		Name:    "User Subscriptions API",
+		Version: "1.0",
+		Links: map[string]string{
+			"swagger": "/v1/swagger/index.html",
+			"health":  "/healthz/detailed",
+			"metrics": "/metrics",
+		},
+	}, &MockLogger{})
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"status": "success",
+		"data": {
+			"name": "User Subscriptions API",
+			"version": "1.0",
+			"links": {
+				"swagger": "/v1/swagger/index.html",
+				"health": "/healthz/detailed",
+				"metrics": "/metrics"
+			}
+		}
+	}`, w.Body.String())
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -22,7 +23,42 @@ type Logger struct {
 
 var _ LoggerInterface = (*Logger)(nil)
 
-func New(level string) (*Logger, error) {
+type options struct {
+	utc bool
+}
+
+type Option func(*options)
+
+// WithUTC writes timestamps in UTC instead of the local time zone.
+func WithUTC() Option {
+	return func(o *options) {
+		o.utc = true
+	}
+}
+
+// New builds a JSON logger writing to stdout. level must be one of debug, info,
+// warn or error.
+func New(level string, opts ...Option) (*Logger, error) {
+	return newLogger(level, "stdout", opts)
+}
+
+// NewStderr is like New but writes to stderr, for commands whose stdout is
+// their output.
+func NewStderr(level string, opts ...Option) (*Logger, error) {
+	return newLogger(level, "stderr", opts)
+}
+
+func newLogger(level, output string, opts []Option) (*Logger, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	timeEncoder := zapcore.ISO8601TimeEncoder
+	if o.utc {
+		timeEncoder = utcTimeEncoder
+	}
+
 	var zapLevel zapcore.Level
 	switch level {
 	case "debug":
@@ -34,7 +70,7 @@ func New(level string) (*Logger, error) {
 	case "error":
 		zapLevel = zapcore.ErrorLevel
 	default:
-		zapLevel = zapcore.InfoLevel
+		return nil, fmt.Errorf("invalid log level %q: must be one of debug, info, warn, error", level)
 	}
 
 	config := zap.Config{
@@ -52,11 +88,11 @@ func New(level string) (*Logger, error) {
 			StacktraceKey:  "stacktrace",
 			LineEnding:     zapcore.DefaultLineEnding,
 			EncodeLevel:    zapcore.LowercaseLevelEncoder,
-			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			EncodeTime:     timeEncoder,
 			EncodeDuration: zapcore.SecondsDurationEncoder,
 			EncodeCaller:   zapcore.ShortCallerEncoder,
 		},
-		OutputPaths:      []string{"stdout"},
+		OutputPaths:      []string{output},
 		ErrorOutputPaths: []string{"stderr"},
 	}
 
@@ -68,6 +104,11 @@ func New(level string) (*Logger, error) {
 	return &Logger{zapLogger}, nil
 }
 
+// utcTimeEncoder is zapcore.ISO8601TimeEncoder converting the time to UTC first.
+func utcTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	zapcore.ISO8601TimeEncoder(t.UTC(), enc)
+}
+
 func (l *Logger) Info(message string, fields map[string]any) {
 	if fields == nil {
 		fields = make(map[string]any)
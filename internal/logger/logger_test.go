@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNew_ValidLevels(t *testing.T) {
+	for _, level := range []string{"debug", "info", "warn", "error"} {
+		t.Run(level, func(t *testing.T) {
+			log, err := New(level)
+
+			assert.NoError(t, err)
+			assert.NotNil(t, log)
+		})
+	}
+}
+
+func TestNew_InvalidLevel(t *testing.T) {
+	log, err := New("infoo")
+
+	assert.ErrorContains(t, err, `invalid log level "infoo"`)
+	assert.Nil(t, log)
+}
+
+func TestNewStderr(t *testing.T) {
+	log, err := NewStderr("info")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, log)
+
+	_, err = NewStderr("infoo")
+
+	assert.Error(t, err)
+}
+
+func TestUTCTimeEncoder(t *testing.T) {
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{TimeKey: "timestamp", EncodeTime: utcTimeEncoder})
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	buf, err := encoder.EncodeEntry(zapcore.Entry{Time: time.Date(2025, 6, 1, 15, 4, 5, 0, moscow)}, nil)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"timestamp":"2025-06-01T12:04:05.000Z"}`, buf.String())
+}
+
+func TestNew_WithUTC(t *testing.T) {
+	log, err := New("info", WithUTC())
+
+	assert.NoError(t, err)
+	assert.NotNil(t, log)
+}
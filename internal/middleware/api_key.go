@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// APIKeyHeader is the request header carrying the API key of the debug and
+// admin endpoints.
+const APIKeyHeader = "X-API-Key"
+
+// APIKey returns a middleware rejecting with 401 the requests whose header
+// does not hold key. Routes matched by exempt are served without a key.
+func APIKey(header, key string, exempt Exemptions, log logger.LoggerInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt.Match(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get(header)), []byte(key)) != 1 {
+				log.Warn("Unauthorized request", map[string]any{"path": r.URL.Path})
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"status":"error","data":null,"error":"Unauthorized"}` + "\n"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
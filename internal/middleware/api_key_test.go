@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey(t *testing.T) {
+	exempt := Exemptions{"/healthz/*", "/metrics", "/v1/swagger/*"}
+
+	tests := []struct {
+		name     string
+		path     string
+		key      string
+		expected int
+	}{
+		{name: "Exempted route without key", path: "/metrics", expected: http.StatusOK},
+		{name: "Exempted prefix without key", path: "/healthz/detailed", expected: http.StatusOK},
+		{name: "Exempted prefix root without key", path: "/v1/swagger/", expected: http.StatusOK},
+		{name: "Exact pattern is not a prefix", path: "/metrics/extra", expected: http.StatusUnauthorized},
+		{name: "Route without key", path: "/v1/debug/config", expected: http.StatusUnauthorized},
+		{name: "Route with wrong key", path: "/v1/debug/config", key: "wrong-key", expected: http.StatusUnauthorized},
+		{name: "Route with key", path: "/v1/debug/config", key: "secret", expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := APIKey("X-API-Key", "secret", exempt, &MockLogger{})(okHandler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.expected == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"status":"error","data":null,"error":"Unauthorized"}`, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+const DefaultBodyLogMaxBytes = 1024
+
+// unparseableBody replaces a logged body that is not JSON: it cannot be
+// masked, so none of it is logged.
+const unparseableBody = "[unparseable]"
+
+// truncatedSuffix marks a logged body cut at the size limit.
+const truncatedSuffix = "...(truncated)"
+
+// sensitiveFields are JSON keys whose values are masked before bodies are logged.
+var sensitiveFields = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"api_key":       true,
+	"secret":        true,
+	"authorization": true,
+}
+
+// BodyLogger returns a middleware logging request and response bodies at debug
+// level, masked and truncated to maxBytes. Only maxBytes+1 bytes of each body
+// are kept for the log; the handler still reads the whole request body. A body
+// over the limit is logged as its masked JSON prefix followed by
+// truncatedSuffix. A body that does not parse as JSON is logged as
+// unparseableBody. It is a no-op unless level is "debug".
+func BodyLogger(log logger.LoggerInterface, level string, maxBytes int) func(http.Handler) http.Handler {
+	if maxBytes <= 0 {
+		maxBytes = DefaultBodyLogMaxBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		if level != "debug" {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var reqBody []byte
+			if r.Body != nil {
+				var err error
+				reqBody, err = io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+				if err != nil {
+					log.Error("Failed to read request body", map[string]any{"error": err})
+				}
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(reqBody), r.Body), Closer: r.Body}
+			}
+
+			rec := &bodyRecorder{ResponseWriter: w, status: http.StatusOK, limit: maxBytes}
+			next.ServeHTTP(rec, r)
+
+			log.Debug("HTTP body", map[string]any{
+				"method":        r.Method,
+				"path":          r.URL.Path,
+				"client_ip":     ClientIP(r),
+				"status":        rec.status,
+				"request_body":  logBody(reqBody, maxBytes),
+				"response_body": logBody(rec.body.Bytes(), maxBytes),
+			})
+		})
+	}
+}
+
+// readCloser reads from Reader and closes Closer, to put the bytes read for
+// the log back in front of the rest of a request body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	limit  int
+	body   bytes.Buffer
+}
+
+func (r *bodyRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	// Keep one byte over the limit so truncation is detectable after masking.
+	if remaining := r.limit + 1 - r.body.Len(); remaining > 0 {
+		r.body.Write(b[:min(len(b), remaining)])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// logBody returns body masked and cut to maxBytes for the log. A body over the
+// limit, which no longer parses as a whole, is masked token by token up to the
+// limit.
+func logBody(body []byte, maxBytes int) string {
+	if len(body) <= maxBytes {
+		return truncate(maskSensitive(body), maxBytes)
+	}
+
+	prefix, ok := maskSensitivePrefix(body[:maxBytes])
+	if !ok {
+		return unparseableBody
+	}
+	return string(prefix[:min(len(prefix), maxBytes)]) + truncatedSuffix
+}
+
+// maskSensitive returns body with the sensitive fields masked, or
+// unparseableBody when it is not JSON.
+func maskSensitive(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return []byte(unparseableBody)
+	}
+
+	masked, err := json.Marshal(maskValue(payload))
+	if err != nil {
+		return []byte(unparseableBody)
+	}
+	return masked
+}
+
+func maskValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = "***"
+				continue
+			}
+			v[key] = maskValue(nested)
+		}
+	case []any:
+		for i, nested := range v {
+			v[i] = maskValue(nested)
+		}
+	}
+	return value
+}
+
+// maskSensitivePrefix re-encodes the complete JSON tokens at the start of
+// body, such as a body cut at the size limit, masking the values of sensitive
+// fields whole. It stops at the first incomplete or invalid token and reports
+// false when there is none before it.
+func maskSensitivePrefix(body []byte) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	// containers holds, for each open object or array, whether it is an
+	// object and how many keys and values were written to it.
+	type container struct {
+		object bool
+		tokens int
+	}
+	var (
+		out        bytes.Buffer
+		containers []container
+		maskNext   bool
+		skipDepth  int
+	)
+
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		// Drop the tokens of a masked object or array.
+		if skipDepth > 0 {
+			if delim, ok := token.(json.Delim); ok {
+				if delim == '{' || delim == '[' {
+					skipDepth++
+				} else {
+					skipDepth--
+				}
+			}
+			continue
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			containers = containers[:len(containers)-1]
+			out.WriteByte(byte(delim))
+			continue
+		}
+
+		isKey := false
+		if n := len(containers); n > 0 {
+			top := &containers[n-1]
+			isKey = top.object && top.tokens%2 == 0
+			switch {
+			case top.object && !isKey:
+				out.WriteByte(':')
+			case top.tokens > 0:
+				out.WriteByte(',')
+			}
+			top.tokens++
+		}
+
+		if maskNext {
+			maskNext = false
+			out.WriteString(`"***"`)
+			if delim, ok := token.(json.Delim); ok && (delim == '{' || delim == '[') {
+				skipDepth = 1
+			}
+			continue
+		}
+
+		switch v := token.(type) {
+		case json.Delim:
+			out.WriteByte(byte(v))
+			containers = append(containers, container{object: v == '{'})
+		case string:
+			encoded, _ := json.Marshal(v)
+			out.Write(encoded)
+			maskNext = isKey && sensitiveFields[strings.ToLower(v)]
+		case json.Number:
+			out.WriteString(v.String())
+		case bool:
+			out.WriteString(strconv.FormatBool(v))
+		case nil:
+			out.WriteString("null")
+		}
+	}
+
+	if out.Len() == 0 {
+		return nil, false
+	}
+	return out.Bytes(), true
+}
+
+func truncate(body []byte, maxBytes int) string {
+	if len(body) > maxBytes {
+		return string(body[:maxBytes]) + truncatedSuffix
+	}
+	return string(body)
+}
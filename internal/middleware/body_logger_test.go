@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+})
+
+func TestBodyLogger_InfoLevelLogsNothing(t *testing.T) {
+	mockLog := &MockLogger{}
+	handler := BodyLogger(mockLog, "info", 16)(echoHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", strings.NewReader(`{"service_name":"Netflix"}`))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"service_name":"Netflix"}`, w.Body.String())
+	assert.Empty(t, mockLog.Debugs)
+}
+
+func TestBodyLogger_DebugLevelLogsBodies(t *testing.T) {
+	mockLog := &MockLogger{}
+	handler := BodyLogger(mockLog, "debug", 64)(echoHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", strings.NewReader(`{"service_name":"Netflix Premium"}`))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, `{"service_name":"Netflix Premium"}`, w.Body.String())
+	if assert.Len(t, mockLog.Debugs, 1) {
+		fields := mockLog.Debugs[0]
+		assert.Equal(t, `{"service_name":"Netflix Premium"}`, fields["request_body"])
+		assert.Equal(t, `{"service_name":"Netflix Premium"}`, fields["response_body"])
+		assert.Equal(t, http.StatusCreated, fields["status"])
+		assert.Equal(t, "192.0.2.1", fields["client_ip"])
+	}
+}
+
+func TestBodyLogger_OversizedBodiesAreTruncated(t *testing.T) {
+	mockLog := &MockLogger{}
+	handler := BodyLogger(mockLog, "debug", 60)(echoHandler)
+
+	body := `{"password":"secret-value","service_name":"Netflix Premium","price":400}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, body, w.Body.String(), "handler must still read the full body")
+	if assert.Len(t, mockLog.Debugs, 1) {
+		fields := mockLog.Debugs[0]
+		assert.Equal(t, `{"password":"***","service_name":"Netflix Premium"...(truncated)`, fields["request_body"])
+		assert.Equal(t, `{"password":"***","service_name":"Netflix Premium"...(truncated)`, fields["response_body"])
+	}
+}
+
+func TestLogBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		maxBytes int
+		expected string
+	}{
+		{name: "Within limit", body: `{"service_name":"Netflix"}`, maxBytes: 64, expected: `{"service_name":"Netflix"}`},
+		{name: "Empty", body: "", maxBytes: 64, expected: ""},
+		{name: "Longer once masked", body: `{"token":"a","x":1}`, maxBytes: 19, expected: `{"token":"***","x":...(truncated)`},
+		{name: "Cut inside a sensitive value", body: `{"service_name":"Netflix","password":"secret-value"}`, maxBytes: 44, expected: `{"service_name":"Netflix","password"...(truncated)`},
+		{name: "Cut inside a masked object", body: `{"secret":{"key":"value","other":"value"},"price":1}`, maxBytes: 30, expected: `{"secret":"***"...(truncated)`},
+		{name: "Masked object before the cut", body: `{"secret":{"key":"value"},"items":[1,true,null,"a"],"price":1}`, maxBytes: 51, expected: `{"secret":"***","items":[1,true,null,"a"]...(truncated)`},
+		{name: "Nested sensitive field", body: `[{"api_key":"abc"},{"api_key":"def"}]`, maxBytes: 30, expected: `[{"api_key":"***"},{"api_key"...(truncated)`},
+		{name: "Oversized non-JSON", body: "password=secret-value", maxBytes: 8, expected: "[unparseable]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, logBody([]byte(tt.body), tt.maxBytes))
+		})
+	}
+}
+
+func TestBodyLogger_NonJSONBodiesAreNotLogged(t *testing.T) {
+	mockLog := &MockLogger{}
+	handler := BodyLogger(mockLog, "debug", 1024)(echoHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", strings.NewReader("password=secret-value"))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if assert.Len(t, mockLog.Debugs, 1) {
+		assert.Equal(t, "[unparseable]", mockLog.Debugs[0]["request_body"])
+	}
+}
+
+func TestBodyLogger_ReadsRequestUpToLimit(t *testing.T) {
+	mockLog := &MockLogger{}
+	body := &countingReader{Reader: strings.NewReader(strings.Repeat("x", 4096))}
+	var read int
+	handler := BodyLogger(mockLog, "debug", 16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the logged prefix has been consumed when the handler starts.
+		assert.Equal(t, 17, body.n)
+		data, _ := io.ReadAll(r.Body)
+		read = len(data)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", body)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, 4096, read)
+}
+
+// countingReader counts the bytes read from Reader.
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestBodyLogger_MasksSensitiveFields(t *testing.T) {
+	mockLog := &MockLogger{}
+	handler := BodyLogger(mockLog, "debug", 1024)(echoHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", strings.NewReader(`{"api_key":"secret-value"}`))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if assert.Len(t, mockLog.Debugs, 1) {
+		assert.Equal(t, `{"api_key":"***"}`, mockLog.Debugs[0]["request_body"])
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// TrustedProxies returns a middleware resolving the client IP of requests, see
+// ClientIP. X-Forwarded-For is honored only when the immediate peer is within
+// trusted: its hops are walked from the nearest one, skipping trusted proxies,
+// and the first untrusted hop is the client. Otherwise the peer address is the
+// client. The resolved IP also replaces r.RemoteAddr so that the access log
+// shows it.
+func TrustedProxies(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+			r = r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip))
+			r.RemoteAddr = ip
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP returns the client IP of r as resolved by TrustedProxies, or the
+// host of r.RemoteAddr for requests it did not handle.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteHost(r.RemoteAddr)
+}
+
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer := remoteHost(r.RemoteAddr)
+	client, err := netip.ParseAddr(peer)
+	if err != nil || !isTrusted(client, trusted) {
+		return peer
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Hops before a malformed one cannot be attributed; the last
+			// trusted proxy is the best known client.
+			break
+		}
+		client = hop
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+	return client.Unmap().String()
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteHost strips the port of a RemoteAddr, if any.
+func remoteHost(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
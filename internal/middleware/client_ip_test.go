@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.1/32")}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		expected     string
+	}{
+		{name: "Untrusted peer without header", remoteAddr: "203.0.113.7:41000", expected: "203.0.113.7"},
+		{name: "Untrusted peer spoofing header", remoteAddr: "203.0.113.7:41000", forwardedFor: []string{"198.51.100.1"}, expected: "203.0.113.7"},
+		{name: "Trusted peer", remoteAddr: "10.0.0.5:41000", forwardedFor: []string{"198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "Trusted peer without header", remoteAddr: "10.0.0.5:41000", expected: "10.0.0.5"},
+		{name: "Chain of trusted proxies", remoteAddr: "10.0.0.5:41000", forwardedFor: []string{"198.51.100.1, 192.168.1.1, 10.1.2.3"}, expected: "198.51.100.1"},
+		{name: "Client spoofing the first hop", remoteAddr: "10.0.0.5:41000", forwardedFor: []string{"1.2.3.4, 198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "Repeated headers", remoteAddr: "10.0.0.5:41000", forwardedFor: []string{"1.2.3.4", "198.51.100.1, 10.1.2.3"}, expected: "198.51.100.1"},
+		{name: "Malformed hop", remoteAddr: "10.0.0.5:41000", forwardedFor: []string{"198.51.100.1, garbage, 10.1.2.3"}, expected: "10.1.2.3"},
+		{name: "IPv4-mapped peer", remoteAddr: "[::ffff:10.0.0.5]:41000", forwardedFor: []string{"198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "IPv6 client", remoteAddr: "10.0.0.5:41000", forwardedFor: []string{"2001:db8::1"}, expected: "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clientIP, remoteAddr string
+			handler := TrustedProxies(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				clientIP = ClientIP(r)
+				remoteAddr = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expected, clientIP)
+			assert.Equal(t, tt.expected, remoteAddr)
+		})
+	}
+}
+
+func TestTrustedProxies_NoneTrusted(t *testing.T) {
+	var clientIP string
+	handler := TrustedProxies(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP = ClientIP(r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	req.RemoteAddr = "10.0.0.5:41000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "10.0.0.5", clientIP)
+}
+
+func TestClientIP_WithoutMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	req.RemoteAddr = "203.0.113.7:41000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	assert.Equal(t, "203.0.113.7", ClientIP(req))
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// ConcurrencyLimiter returns a middleware serving at most limit requests at a
+// time. Requests beyond the limit are rejected with 429 instead of queueing.
+// A non-positive limit disables limiting. Every handler wrapped by the
+// returned middleware shares the same limit.
+func ConcurrencyLimiter(limit int, log logger.LoggerInterface) func(http.Handler) http.Handler {
+	var slots chan struct{}
+	if limit > 0 {
+		slots = make(chan struct{}, limit)
+	}
+
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				next.ServeHTTP(w, r)
+			default:
+				log.Warn("Rejecting request over concurrency limit", map[string]any{"path": r.URL.Path, "limit": limit})
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"status":"error","data":null,"error":"Too many concurrent requests, retry later"}` + "\n"))
+			}
+		})
+	}
+}
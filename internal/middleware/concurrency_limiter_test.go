@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimiter_RejectsOverLimit(t *testing.T) {
+	const limit = 2
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := ConcurrencyLimiter(limit, &MockLogger{})(blocking)
+
+	codes := make([]int, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost", nil))
+			codes[i] = w.Code
+		}(i)
+	}
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+
+	w = httptest.NewRecorder()
+	go func() { <-started }()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "slots are released after requests finish")
+}
+
+func TestConcurrencyLimiter_SharedAcrossHandlers(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	limiter := ConcurrencyLimiter(1, &MockLogger{})
+	cost := limiter(blocking)
+	rolling := limiter(okHandler)
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		cost.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost", nil))
+		done <- w.Code
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	rolling.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost/rolling", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "the second handler uses the slot taken by the first")
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestConcurrencyLimiter_Disabled(t *testing.T) {
+	handler := ConcurrencyLimiter(0, &MockLogger{})(okHandler)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
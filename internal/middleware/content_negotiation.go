@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// defaultProduces is assumed for operations the spec declares no types for.
+var defaultProduces = []string{"application/json"}
+
+// ContentNegotiator returns a middleware rejecting requests with 406 when their
+// Accept header excludes every type the operation produces according to the
+// generated Swagger 2.0 spec. Requests without an Accept header, accepting */*
+// or for routes missing from the spec are passed through to the handler.
+func ContentNegotiator(swaggerJSON []byte, log logger.LoggerInterface) (func(http.Handler) http.Handler, error) {
+	spec, doc, err := parseSpec(swaggerJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build spec router: %w", err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept := r.Header.Get("Accept")
+			if accept == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			route, _, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			produces := spec.Produces
+			if item := spec.Paths[route.Path]; item != nil {
+				if op := item.GetOperation(route.Method); op != nil && len(op.Produces) > 0 {
+					produces = op.Produces
+				}
+			}
+			if len(produces) == 0 {
+				produces = defaultProduces
+			}
+
+			if !acceptsAny(accept, produces) {
+				log.Warn("Rejecting request with unsupported Accept", map[string]any{"path": r.URL.Path, "accept": accept})
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotAcceptable)
+				_, _ = w.Write([]byte(`{"status":"error","data":null,"error":"Not acceptable, supported types: ` + strings.Join(produces, ", ") + `"}` + "\n"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// acceptsAny reports whether the Accept header value allows one of types.
+// Media ranges with q=0 are excluded; unparsable ranges are ignored, so a
+// header without any valid range accepts everything.
+func acceptsAny(accept string, types []string) bool {
+	explicit := false
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		explicit = true
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+
+		for _, t := range types {
+			if matchesMediaRange(mediaRange, t) {
+				return true
+			}
+		}
+	}
+	return !explicit
+}
+
+// acceptsPretty reports whether the Accept header value asks for indented JSON
+// with a pretty parameter, as in application/json; pretty=1, on a media range
+// allowing JSON.
+func acceptsPretty(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || !matchesMediaRange(mediaRange, "application/json") {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		if pretty, err := strconv.ParseBool(params["pretty"]); err == nil && pretty {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMediaRange(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
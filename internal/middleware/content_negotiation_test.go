@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/n-korel/user-subscriptions-api/docs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentNegotiator(t *testing.T) {
+	negotiator, err := ContentNegotiator([]byte(docs.SwaggerInfo.ReadDoc()), &MockLogger{})
+	if err != nil {
+		t.Fatalf("failed to build negotiator: %v", err)
+	}
+	handler := negotiator(okHandler)
+
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+		status int
+	}{
+		{name: "No Accept", path: "/v1/subscriptions", accept: "", status: http.StatusOK},
+		{name: "Wildcard", path: "/v1/subscriptions", accept: "*/*", status: http.StatusOK},
+		{name: "JSON", path: "/v1/subscriptions", accept: "application/json", status: http.StatusOK},
+		{name: "Type wildcard", path: "/v1/subscriptions", accept: "application/*", status: http.StatusOK},
+		{name: "Browser default", path: "/v1/subscriptions", accept: "text/html,application/xhtml+xml,*/*;q=0.8", status: http.StatusOK},
+		{name: "Unsupported type", path: "/v1/subscriptions", accept: "application/pdf", status: http.StatusNotAcceptable},
+		{name: "Excluded with q=0", path: "/v1/subscriptions", accept: "application/json;q=0", status: http.StatusNotAcceptable},
+		{name: "Export as CSV", path: "/v1/subscriptions/export", accept: "text/csv", status: http.StatusOK},
+		{name: "Export as JSON", path: "/v1/subscriptions/export", accept: "application/json", status: http.StatusNotAcceptable},
+		{name: "Route missing from spec", path: "/metrics", accept: "application/pdf", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusNotAcceptable {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), `"data":null`)
+			}
+		})
+	}
+}
+
+func TestAcceptsPretty(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{accept: "", expected: false},
+		{accept: "application/json", expected: false},
+		{accept: "application/json; pretty=1", expected: true},
+		{accept: "application/json;pretty=true", expected: true},
+		{accept: "application/json; pretty=0", expected: false},
+		{accept: "application/json; pretty=yes", expected: false},
+		{accept: "text/csv, application/json; pretty=1; q=0.5", expected: true},
+		{accept: "*/*; pretty=1", expected: true},
+		{accept: "application/*; pretty=1", expected: true},
+		{accept: "text/csv; pretty=1", expected: false},
+		{accept: "application/json; pretty=1; q=0", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			assert.Equal(t, tt.expected, acceptsPretty(tt.accept))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockLogger struct {
+	Warnings []string
+	Debugs   []map[string]any
+}
+
+func (m *MockLogger) Info(message string, fields map[string]any)  {}
+func (m *MockLogger) Error(message string, fields map[string]any) {}
+func (m *MockLogger) Warn(message string, fields map[string]any) {
+	m.Warnings = append(m.Warnings, message)
+}
+func (m *MockLogger) Debug(message string, fields map[string]any) {
+	m.Debugs = append(m.Debugs, fields)
+}
+func (m *MockLogger) Fatal(message string, fields map[string]any) {}
+func (m *MockLogger) Sync() error                                 { return nil }
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestCORS_PreflightMaxAge(t *testing.T) {
+	mockLog := &MockLogger{}
+	handler := CORS(CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		MaxAge:           600,
+		AllowCredentials: true,
+	}, mockLog)(okHandler)
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/subscriptions", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, mockLog.Warnings)
+}
+
+func TestCORS_CredentialsWithWildcardOrigin(t *testing.T) {
+	mockLog := &MockLogger{}
+	handler := CORS(CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	}, mockLog)(okHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Len(t, mockLog.Warnings, 1)
+}
+
+func TestCORS_DisallowedOrigin(t *testing.T) {
+	handler := CORS(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, &MockLogger{})(okHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_ExemptRoute(t *testing.T) {
+	handler := CORS(CORSConfig{AllowedOrigins: []string{"*"}, Exempt: Exemptions{"/metrics"}}, &MockLogger{})(okHandler)
+
+	for path, allowOrigin := range map[string]string{"/metrics": "", "/v1/subscriptions": "*"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, allowOrigin, w.Header().Get("Access-Control-Allow-Origin"), path)
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// DuplicateQueryParams returns a middleware rejecting with 400 requests that
+// repeat a single-valued query parameter, e.g. ?user_id=a&user_id=b, which
+// handlers would otherwise silently read as its first value. Parameters the
+// generated Swagger 2.0 spec declares as arrays may repeat. Routes missing from
+// the spec are passed through to the handler.
+func DuplicateQueryParams(swaggerJSON []byte, log logger.LoggerInterface) (func(http.Handler) http.Handler, error) {
+	_, doc, err := parseSpec(swaggerJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build spec router: %w", err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+
+			var repeated []string
+			for name, values := range query {
+				if len(values) > 1 {
+					repeated = append(repeated, name)
+				}
+			}
+			if len(repeated) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			route, _, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Report the first offending name in a stable order.
+			sort.Strings(repeated)
+			for _, name := range repeated {
+				if isRepeatable(route.PathItem.Parameters, route.Operation.Parameters, name) {
+					continue
+				}
+
+				log.Warn("Rejecting duplicate query parameter", map[string]any{"path": r.URL.Path, "param": name})
+				writeValidationError(w, "duplicate query parameter: "+name)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// isRepeatable reports whether the query parameter name is declared as an
+// array by the operation or its path.
+func isRepeatable(pathParams, operationParams openapi3.Parameters, name string) bool {
+	param := operationParams.GetByInAndName(openapi3.ParameterInQuery, name)
+	if param == nil {
+		param = pathParams.GetByInAndName(openapi3.ParameterInQuery, name)
+	}
+	if param == nil || param.Schema == nil || param.Schema.Value == nil {
+		return false
+	}
+	return param.Schema.Value.Type.Is(openapi3.TypeArray)
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/n-korel/user-subscriptions-api/docs"
+	"github.com/stretchr/testify/assert"
+)
+
+// repeatableSpec declares an array query parameter, which generated specs do
+// not have yet.
+const repeatableSpec = `{
+	"swagger": "2.0",
+	"info": {"title": "test", "version": "1.0"},
+	"basePath": "/v1",
+	"paths": {
+		"/subscriptions/cost": {
+			"get": {
+				"parameters": [
+					{"name": "service_name", "in": "query", "type": "array", "items": {"type": "string"}, "collectionFormat": "multi"},
+					{"name": "user_id", "in": "query", "type": "string"}
+				],
+				"responses": {"200": {"description": "OK"}}
+			}
+		}
+	}
+}`
+
+func TestDuplicateQueryParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   string
+		query  string
+		status int
+		error  string
+	}{
+		{name: "Single values", spec: docs.SwaggerInfo.ReadDoc(), query: "/v1/subscriptions/cost?start_date=01-2025&user_id=a", status: http.StatusOK},
+		{name: "Duplicate single-valued", spec: docs.SwaggerInfo.ReadDoc(), query: "/v1/subscriptions/cost?start_date=01-2025&user_id=a&user_id=b", status: http.StatusBadRequest, error: "duplicate query parameter: user_id"},
+		{name: "Duplicate undeclared", spec: docs.SwaggerInfo.ReadDoc(), query: "/v1/subscriptions?debug=1&debug=2", status: http.StatusBadRequest, error: "duplicate query parameter: debug"},
+		{name: "Route missing from spec", spec: docs.SwaggerInfo.ReadDoc(), query: "/metrics?name=a&name=b", status: http.StatusOK},
+		{name: "Repeated array", spec: repeatableSpec, query: "/v1/subscriptions/cost?service_name=Netflix&service_name=Spotify", status: http.StatusOK},
+		{name: "Repeated array with duplicate single-valued", spec: repeatableSpec, query: "/v1/subscriptions/cost?service_name=Netflix&service_name=Spotify&user_id=a&user_id=b", status: http.StatusBadRequest, error: "duplicate query parameter: user_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware, err := DuplicateQueryParams([]byte(tt.spec), &MockLogger{})
+			if err != nil {
+				t.Fatalf("failed to build middleware: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.query, nil)
+			w := httptest.NewRecorder()
+
+			middleware(okHandler).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.error != "" {
+				assert.JSONEq(t, `{"status":"error","data":null,"error":"`+tt.error+`"}`, w.Body.String())
+			}
+		})
+	}
+}
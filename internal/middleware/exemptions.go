@@ -0,0 +1,22 @@
+package middleware
+
+import "strings"
+
+// Exemptions lists the route patterns skipping the auth and CORS middleware,
+// such as /metrics or /v1/swagger/*. A pattern matches the request path
+// exactly, or every path starting with it when it ends with *.
+type Exemptions []string
+
+// Match reports whether path is exempted.
+func (e Exemptions) Match(path string) bool {
+	for _, pattern := range e {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
+}
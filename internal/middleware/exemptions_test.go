@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExemptions_Match(t *testing.T) {
+	exempt := Exemptions{"/metrics", "/v1/swagger/*", "/healthz"}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "Exact path", path: "/metrics", want: true},
+		{name: "Exact path with suffix", path: "/metrics/extra", want: false},
+		{name: "Exact path with trailing slash", path: "/healthz/", want: false},
+		{name: "Prefix itself", path: "/v1/swagger/", want: true},
+		{name: "Under prefix", path: "/v1/swagger/index.html", want: true},
+		{name: "Nested under prefix", path: "/v1/swagger/assets/app.js", want: true},
+		{name: "Prefix without its slash", path: "/v1/swagger", want: false},
+		{name: "Sibling of prefix", path: "/v1/swaggerui", want: false},
+		{name: "Unlisted path", path: "/v1/subscriptions", want: false},
+		{name: "Root", path: "/", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exempt.Match(tt.path))
+		})
+	}
+}
+
+func TestExemptions_MatchEmpty(t *testing.T) {
+	assert.False(t, Exemptions(nil).Match("/metrics"))
+	assert.True(t, Exemptions{"*"}.Match("/v1/subscriptions"), "a bare * exempts every path")
+}
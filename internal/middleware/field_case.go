@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// fieldCaseHeader selects the key case of JSON responses, like the case query parameter.
+const fieldCaseHeader = "X-Field-Case"
+
+// FieldCase returns a middleware rewriting the keys of JSON responses from
+// snake_case to camelCase when the request asks for it with ?case=camel or an
+// X-Field-Case: camel header. snake, the default, leaves responses untouched;
+// other values are rejected with 400. Responses carry Vary: X-Field-Case.
+func FieldCase(log logger.LoggerInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", fieldCaseHeader)
+
+			fieldCase := r.URL.Query().Get("case")
+			if fieldCase == "" {
+				fieldCase = r.Header.Get(fieldCaseHeader)
+			}
+
+			switch fieldCase {
+			case "", "snake":
+				next.ServeHTTP(w, r)
+				return
+			case "camel":
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"status":"error","data":null,"error":"case must be snake or camel"}` + "\n"))
+				return
+			}
+
+			rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			body := rec.body.Bytes()
+			if strings.HasPrefix(rec.header.Get("Content-Type"), "application/json") {
+				if converted, err := camelCaseKeys(body); err != nil {
+					log.Warn("Failed to convert response keys", map[string]any{"path": r.URL.Path, "error": err})
+				} else {
+					body = converted
+					rec.header.Set("Content-Length", strconv.Itoa(len(body)))
+				}
+			}
+
+			rec.copyHeader(w.Header())
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(body)
+		})
+	}
+}
+
+// bufferedResponse holds a response until it is complete.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// copyHeader sets the header of the response on dst, adding to rather than
+// replacing the Vary values already there.
+func (b *bufferedResponse) copyHeader(dst http.Header) {
+	for key, values := range b.header {
+		if key == "Vary" {
+			dst[key] = append(dst[key], values...)
+			continue
+		}
+		dst[key] = values
+	}
+}
+
+// camelCaseKeys converts every object key of a JSON document, at any depth.
+func camelCaseKeys(body []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(convertKeys(value)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func convertKeys(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			converted[snakeToCamel(key)] = convertKeys(item)
+		}
+		return converted
+	case []any:
+		for i, item := range v {
+			v[i] = convertKeys(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// snakeToCamel converts a snake_case key such as service_name to serviceName.
+func snakeToCamel(key string) string {
+	parts := strings.Split(key, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var subscriptionHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte(`{"status":"success","data":{"service_name":"Netflix","price":100,"end_date":null,"items":[{"total_cost":1200}]}}` + "\n"))
+})
+
+func TestFieldCase(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		header string
+		status int
+		body   string
+	}{
+		{
+			name:   "Default is snake_case",
+			target: "/v1/subscriptions",
+			status: http.StatusCreated,
+			body:   `{"status":"success","data":{"service_name":"Netflix","price":100,"end_date":null,"items":[{"total_cost":1200}]}}`,
+		},
+		{
+			name:   "Query parameter",
+			target: "/v1/subscriptions?case=camel",
+			status: http.StatusCreated,
+			body:   `{"status":"success","data":{"serviceName":"Netflix","price":100,"endDate":null,"items":[{"totalCost":1200}]}}`,
+		},
+		{
+			name:   "Header",
+			target: "/v1/subscriptions",
+			header: "camel",
+			status: http.StatusCreated,
+			body:   `{"status":"success","data":{"serviceName":"Netflix","price":100,"endDate":null,"items":[{"totalCost":1200}]}}`,
+		},
+		{
+			name:   "Unknown case",
+			target: "/v1/subscriptions?case=kebab",
+			status: http.StatusBadRequest,
+			body:   `{"status":"error","data":null,"error":"case must be snake or camel"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := FieldCase(&MockLogger{})(subscriptionHandler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(fieldCaseHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, []string{fieldCaseHeader}, w.Header().Values("Vary"))
+			assert.JSONEq(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestFieldCase_NonJSONUntouched(t *testing.T) {
+	handler := FieldCase(&MockLogger{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("service_name,price\nNetflix,100\n"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/export?case=camel", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, "service_name,price\nNetflix,100\n", w.Body.String())
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// poolSampleInterval limits how often pool statistics are sampled.
+const poolSampleInterval = time.Second
+
+// PoolWaitTime returns a function reporting the average time an acquire had to
+// wait for a free connection during the last sampling interval.
+func PoolWaitTime(pool *pgxpool.Pool) func() time.Duration {
+	var (
+		mu            sync.Mutex
+		sampledAt     time.Time
+		lastAcquires  int64
+		lastWaitTotal time.Duration
+		average       time.Duration
+	)
+
+	return func() time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if time.Since(sampledAt) < poolSampleInterval {
+			return average
+		}
+
+		stat := pool.Stat()
+		acquires := stat.AcquireCount() - lastAcquires
+		waitTotal := stat.EmptyAcquireWaitTime() - lastWaitTotal
+
+		average = 0
+		if acquires > 0 {
+			average = waitTotal / time.Duration(acquires)
+		}
+
+		sampledAt = time.Now()
+		lastAcquires = stat.AcquireCount()
+		lastWaitTotal = stat.EmptyAcquireWaitTime()
+
+		return average
+	}
+}
+
+// LoadShedder returns a middleware rejecting requests with 503 while waitTime
+// exceeds threshold. It is meant for the endpoints holding a connection the
+// longest, such as listings and cost aggregations, applied to each of them
+// rather than to the whole router. A non-positive threshold disables shedding.
+func LoadShedder(threshold time.Duration, waitTime func() time.Duration, log logger.LoggerInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if threshold <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if wait := waitTime(); wait > threshold {
+				log.Warn("Shedding request due to database pool pressure", map[string]any{"path": r.URL.Path, "wait": wait.String()})
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"status":"error","data":null,"error":"Service is overloaded, retry later"}` + "\n"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
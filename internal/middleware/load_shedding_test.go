@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadShedder_HighWaitTime(t *testing.T) {
+	highWait := func() time.Duration { return 500 * time.Millisecond }
+	handler := LoadShedder(100*time.Millisecond, highWait, &MockLogger{})(okHandler)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "Listing", method: http.MethodGet, path: "/v1/subscriptions"},
+		{name: "Cost", method: http.MethodGet, path: "/v1/subscriptions/cost"},
+		{name: "Cost query", method: http.MethodPost, path: "/v1/subscriptions/cost/query"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestLoadShedder_Disabled(t *testing.T) {
+	highWait := func() time.Duration { return 500 * time.Millisecond }
+	handler := LoadShedder(0, highWait, &MockLogger{})(okHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestLoadShedder_LowWaitTime(t *testing.T) {
+	lowWait := func() time.Duration { return 10 * time.Millisecond }
+	handler := LoadShedder(100*time.Millisecond, lowWait, &MockLogger{})(okHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// PrettyJSON returns a middleware indenting JSON responses when the request asks
+// for it with ?pretty=1 or a pretty parameter on the JSON type it accepts, such
+// as Accept: application/json; pretty=1. Responses are compact by default; a
+// pretty query parameter that is not a boolean is rejected with 400. Responses
+// carry Vary: Accept, as conditional requests must not mix the two layouts.
+func PrettyJSON(log logger.LoggerInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept")
+
+			pretty := acceptsPretty(r.Header.Get("Accept"))
+			if value := r.URL.Query().Get("pretty"); value != "" {
+				var err error
+				if pretty, err = strconv.ParseBool(value); err != nil {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"status":"error","data":null,"error":"pretty must be a boolean"}` + "\n"))
+					return
+				}
+			}
+
+			if !pretty {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			body := rec.body.Bytes()
+			if strings.HasPrefix(rec.header.Get("Content-Type"), "application/json") && len(bytes.TrimSpace(body)) > 0 {
+				var indented bytes.Buffer
+				if err := json.Indent(&indented, body, "", "  "); err != nil {
+					log.Warn("Failed to indent response", map[string]any{"path": r.URL.Path, "error": err})
+				} else {
+					body = indented.Bytes()
+					rec.header.Set("Content-Length", strconv.Itoa(len(body)))
+				}
+			}
+
+			rec.copyHeader(w.Header())
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(body)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	compact := `{"status":"success","data":{"service_name":"Netflix","price":100,"end_date":null,"items":[{"total_cost":1200}]}}` + "\n"
+	pretty := `{
+  "status": "success",
+  "data": {
+    "service_name": "Netflix",
+    "price": 100,
+    "end_date": null,
+    "items": [
+      {
+        "total_cost": 1200
+      }
+    ]
+  }
+}
+`
+
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		status int
+		body   string
+	}{
+		{name: "Compact by default", target: "/v1/subscriptions", status: http.StatusCreated, body: compact},
+		{name: "Plain Accept", target: "/v1/subscriptions", accept: "application/json", status: http.StatusCreated, body: compact},
+		{name: "Accept parameter", target: "/v1/subscriptions", accept: "application/json; pretty=1", status: http.StatusCreated, body: pretty},
+		{name: "Query parameter", target: "/v1/subscriptions?pretty=1", status: http.StatusCreated, body: pretty},
+		{name: "Query parameter overrides Accept", target: "/v1/subscriptions?pretty=false", accept: "application/json; pretty=1", status: http.StatusCreated, body: compact},
+		{name: "Invalid query parameter", target: "/v1/subscriptions?pretty=maybe", status: http.StatusBadRequest, body: `{"status":"error","data":null,"error":"pretty must be a boolean"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := PrettyJSON(&MockLogger{})(subscriptionHandler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, []string{"Accept"}, w.Header().Values("Vary"))
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestPrettyJSON_KeepsVaryOfInnerMiddleware(t *testing.T) {
+	handler := PrettyJSON(&MockLogger{})(FieldCase(&MockLogger{})(subscriptionHandler))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions?pretty=1&case=camel", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, []string{"Accept", fieldCaseHeader}, w.Header().Values("Vary"))
+	assert.Contains(t, w.Body.String(), `"serviceName": "Netflix"`)
+}
+
+func TestPrettyJSON_NonJSONResponse(t *testing.T) {
+	csvHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("id,service_name\n1,Netflix\n"))
+	})
+	handler := PrettyJSON(&MockLogger{})(csvHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/export?pretty=1", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, "id,service_name\n1,Netflix\n", w.Body.String())
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// numericStringExtension marks integer fields that also accept their value as a
+// numeric string, e.g. "price": "100".
+const numericStringExtension = "x-numeric-string"
+
+// RequestValidator returns a middleware validating JSON request bodies against
+// the generated Swagger 2.0 spec before the handler runs. Schema violations are
+// rejected with 400. Requests without a body, with malformed JSON or for routes
+// missing from the spec are passed through to the handler.
+func RequestValidator(swaggerJSON []byte, log logger.LoggerInterface) (func(http.Handler) http.Handler, error) {
+	_, doc, err := parseSpec(swaggerJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schema := range doc.Components.Schemas {
+		allowNumericStrings(schema.Value)
+	}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build spec router: %w", err)
+	}
+
+	options := &openapi3filter.Options{
+		ExcludeRequestQueryParams: true,
+		AuthenticationFunc:        openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if len(bytes.TrimSpace(body)) == 0 || !json.Valid(body) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Handlers decode the body as JSON whatever its declared type.
+			validationReq := r.Clone(r.Context())
+			validationReq.Body = io.NopCloser(bytes.NewReader(body))
+			if validationReq.Header.Get("Content-Type") == "" {
+				validationReq.Header.Set("Content-Type", "application/json")
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    validationReq,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				message := violationMessage(err)
+				log.Warn("Request does not match the API schema", map[string]any{"path": r.URL.Path, "error": message})
+				writeValidationError(w, message)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// parseSpec parses the generated Swagger 2.0 spec and converts it to OpenAPI 3
+// for routing and validation.
+func parseSpec(swaggerJSON []byte) (*openapi2.T, *openapi3.T, error) {
+	var spec openapi2.T
+	if err := json.Unmarshal(swaggerJSON, &spec); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse swagger spec: %w", err)
+	}
+
+	doc, err := openapi2conv.ToV3(&spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert swagger spec: %w", err)
+	}
+
+	// Match paths regardless of the host the spec was generated for.
+	doc.Servers = openapi3.Servers{{URL: spec.BasePath}}
+
+	return &spec, doc, nil
+}
+
+// allowNumericStrings lets schemas marked with numericStringExtension accept
+// either an integer or a numeric string.
+func allowNumericStrings(schema *openapi3.Schema) {
+	if schema == nil {
+		return
+	}
+
+	for _, property := range schema.Properties {
+		allowNumericStrings(property.Value)
+	}
+
+	if marked, _ := schema.Extensions[numericStringExtension].(bool); !marked {
+		return
+	}
+
+	schema.OneOf = openapi3.SchemaRefs{
+		openapi3.NewSchemaRef("", &openapi3.Schema{Type: schema.Type}),
+		openapi3.NewSchemaRef("", openapi3.NewStringSchema().WithPattern(`^[+-]?[0-9]+$`)),
+	}
+	schema.Type = nil
+}
+
+// violationMessage describes a validation error as "field: reason" when it is
+// caused by a schema mismatch.
+func violationMessage(err error) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if field := strings.Join(schemaErr.JSONPointer(), "."); field != "" {
+			return field + ": " + schemaErr.Reason
+		}
+		return schemaErr.Reason
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.Error()
+	}
+
+	return err.Error()
+}
+
+func writeValidationError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "error", "data": nil, "error": message})
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/n-korel/user-subscriptions-api/docs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestValidator(t *testing.T) {
+	validator, err := RequestValidator([]byte(docs.SwaggerInfo.ReadDoc()), &MockLogger{})
+	if err != nil {
+		t.Fatalf("failed to build validator: %v", err)
+	}
+	handler := validator(okHandler)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		errMsg string
+	}{
+		{
+			name:   "Valid body",
+			method: http.MethodPost,
+			path:   "/v1/subscriptions",
+			body:   `{"service_name":"Netflix","price":100,"user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2025","end_date":null}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Price as numeric string",
+			method: http.MethodPost,
+			path:   "/v1/subscriptions",
+			body:   `{"service_name":"Netflix","price":"100"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Wrong field type",
+			method: http.MethodPost,
+			path:   "/v1/subscriptions",
+			body:   `{"service_name":42,"price":100}`,
+			status: http.StatusBadRequest,
+			errMsg: "service_name: value must be a string",
+		},
+		{
+			name:   "Non-numeric price",
+			method: http.MethodPatch,
+			path:   "/v1/subscriptions/1",
+			body:   `{"service_name":"Netflix","price":"abc"}`,
+			status: http.StatusBadRequest,
+			errMsg: "price",
+		},
+		{
+			name:   "Empty body is left to the handler",
+			method: http.MethodPost,
+			path:   "/v1/subscriptions",
+			body:   ``,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Malformed JSON is left to the handler",
+			method: http.MethodPost,
+			path:   "/v1/subscriptions",
+			body:   `{"service_name":`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Route missing from the spec",
+			method: http.MethodPost,
+			path:   "/v1/unknown",
+			body:   `{"a":1}`,
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.errMsg != "" {
+				var response map[string]any
+				if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				assert.Equal(t, "error", response["status"])
+				assert.Contains(t, response["error"], tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestRequestValidator_BodyReachesHandler(t *testing.T) {
+	validator, err := RequestValidator([]byte(docs.SwaggerInfo.ReadDoc()), &MockLogger{})
+	if err != nil {
+		t.Fatalf("failed to build validator: %v", err)
+	}
+
+	body := `{"service_name":"Netflix","price":100}`
+	var received string
+	handler := validator(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := new(bytes.Buffer)
+		_, _ = buf.ReadFrom(r.Body)
+		received = buf.String()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewBufferString(body))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, body, received)
+}
+
+func TestRequestValidator_InvalidSpec(t *testing.T) {
+	_, err := RequestValidator([]byte(`not json`), &MockLogger{})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package reminders
+
+import (
+	"context"
+	"time"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+	"github.com/n-korel/user-subscriptions-api/internal/subscriptions"
+)
+
+// monthLayout is the time layout of the MM-YYYY subscription dates.
+const monthLayout = "01-2006"
+
+// Source lists the subscriptions to scan.
+type Source interface {
+	GetAllSubscriptions(ctx context.Context, sort subscriptions.Sort) ([]subscriptions.Subscription, error)
+}
+
+// Notifier delivers a reminder about a subscription that expires at expiresAt.
+type Notifier interface {
+	Notify(ctx context.Context, sub subscriptions.Subscription, expiresAt time.Time) error
+}
+
+// LogNotifier writes reminders to the log.
+type LogNotifier struct {
+	log logger.LoggerInterface
+}
+
+func NewLogNotifier(log logger.LoggerInterface) *LogNotifier {
+	return &LogNotifier{log: log}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, sub subscriptions.Subscription, expiresAt time.Time) error {
+	n.log.Info("Subscription expires soon", map[string]any{
+		"id":           sub.ID,
+		"user_id":      sub.UserID,
+		"service_name": sub.ServiceName,
+		"expires_at":   expiresAt,
+	})
+	return nil
+}
+
+// defaultDrainTimeout bounds how long queued reminders may take to be sent on
+// shutdown.
+const defaultDrainTimeout = 5 * time.Second
+
+// Scheduler periodically looks for subscriptions expiring within a window and
+// sends one reminder per subscription end date.
+type Scheduler struct {
+	source       Source
+	notifier     Notifier
+	log          logger.LoggerInterface
+	interval     time.Duration
+	window       time.Duration
+	drainTimeout time.Duration
+	now          func() time.Time
+
+	// notified maps subscription IDs to the end date they were reminded about.
+	notified map[int]string
+
+	// queue holds the subscriptions due a reminder that are not sent yet.
+	queue []subscriptions.Subscription
+}
+
+type Option func(*Scheduler)
+
+// WithDrainTimeout sets how long queued reminders may take to be sent once
+// Run is cancelled, instead of defaultDrainTimeout. Reminders still queued
+// after it are dropped.
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(s *Scheduler) {
+		s.drainTimeout = timeout
+	}
+}
+
+func NewScheduler(source Source, notifier Notifier, log logger.LoggerInterface, interval, window time.Duration, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		source:       source,
+		notifier:     notifier,
+		log:          log,
+		interval:     interval,
+		window:       window,
+		drainTimeout: defaultDrainTimeout,
+		now:          time.Now,
+		notified:     make(map[int]string),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run scans immediately and then every interval until ctx is cancelled. It
+// then sends the reminders still queued before returning, see
+// WithDrainTimeout.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.log.Info("Reminder scheduler started", map[string]any{"interval": s.interval, "window": s.window})
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.drain(ctx)
+			s.log.Info("Reminder scheduler stopped", nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) scan(ctx context.Context) {
+	subs, err := s.source.GetAllSubscriptions(ctx, subscriptions.Sort{})
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Error("Failed to fetch subscriptions for reminders", map[string]any{"error": err})
+		}
+		return
+	}
+
+	for _, sub := range expiring(subs, s.now(), s.window) {
+		if s.notified[sub.ID] == *sub.EndDate {
+			continue
+		}
+		s.queue = append(s.queue, sub)
+	}
+
+	s.flush(ctx)
+}
+
+// flush sends the queued reminders until the queue is empty or ctx is done.
+// A reminder interrupted by ctx stays queued; one that fails otherwise is
+// dropped and found again by the next scan.
+func (s *Scheduler) flush(ctx context.Context) {
+	for len(s.queue) > 0 && ctx.Err() == nil {
+		sub := s.queue[0]
+
+		if err := s.notifier.Notify(ctx, sub, expiresAt(sub)); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.log.Error("Failed to send reminder", map[string]any{"error": err, "id": sub.ID})
+		} else {
+			s.notified[sub.ID] = *sub.EndDate
+		}
+		s.queue = s.queue[1:]
+	}
+}
+
+// drain sends the reminders left queued by the cancellation of ctx, giving up
+// after the drain timeout.
+func (s *Scheduler) drain(ctx context.Context) {
+	if len(s.queue) == 0 {
+		return
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.drainTimeout)
+	defer cancel()
+
+	s.flush(drainCtx)
+
+	if len(s.queue) > 0 {
+		s.log.Warn("Dropping undelivered reminders", map[string]any{"count": len(s.queue)})
+		s.queue = nil
+	}
+}
+
+// expiring returns the subscriptions that expire after now but no later than
+// now plus window.
+func expiring(subs []subscriptions.Subscription, now time.Time, window time.Duration) []subscriptions.Subscription {
+	deadline := now.Add(window)
+
+	var result []subscriptions.Subscription
+	for _, sub := range subs {
+		if sub.EndDate == nil {
+			continue
+		}
+
+		at := expiresAt(sub)
+		if at.IsZero() {
+			continue
+		}
+
+		if at.After(now) && !at.After(deadline) {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// expiresAt returns the moment a subscription ends: the start of the month
+// following its end_date. It is zero for open-ended or malformed dates.
+func expiresAt(sub subscriptions.Subscription) time.Time {
+	if sub.EndDate == nil {
+		return time.Time{}
+	}
+
+	end, err := time.Parse(monthLayout, *sub.EndDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return end.AddDate(0, 1, 0)
+}
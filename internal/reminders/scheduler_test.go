@@ -0,0 +1,201 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/n-korel/user-subscriptions-api/internal/subscriptions"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockLogger struct {
+	Warnings []string
+}
+
+func (m *MockLogger) Info(message string, fields map[string]any)  {}
+func (m *MockLogger) Error(message string, fields map[string]any) {}
+func (m *MockLogger) Warn(message string, fields map[string]any) {
+	m.Warnings = append(m.Warnings, message)
+}
+func (m *MockLogger) Debug(message string, fields map[string]any) {}
+func (m *MockLogger) Fatal(message string, fields map[string]any) {}
+func (m *MockLogger) Sync() error                                 { return nil }
+
+type MockSource struct {
+	Subscriptions []subscriptions.Subscription
+	Err           error
+}
+
+func (m *MockSource) GetAllSubscriptions(ctx context.Context, sort subscriptions.Sort) ([]subscriptions.Subscription, error) {
+	return m.Subscriptions, m.Err
+}
+
+type MockNotifier struct {
+	Notified []int
+	Err      error
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, sub subscriptions.Subscription, expiresAt time.Time) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Notified = append(m.Notified, sub.ID)
+	return nil
+}
+
+func subscription(id int, endDate string) subscriptions.Subscription {
+	sub := subscriptions.Subscription{ID: id, ServiceName: "Netflix", Price: 100, StartDate: "01-2025"}
+	if endDate != "" {
+		sub.EndDate = &endDate
+	}
+	return sub
+}
+
+func TestExpiring(t *testing.T) {
+	now := time.Date(2025, 3, 25, 12, 0, 0, 0, time.UTC)
+
+	subs := []subscriptions.Subscription{
+		subscription(1, "03-2025"), // expires 2025-04-01, within a week
+		subscription(2, "04-2025"), // expires 2025-05-01, outside the window
+		subscription(3, "02-2025"), // already expired
+		subscription(4, ""),        // open-ended
+		subscription(5, "2025-03"), // malformed
+	}
+
+	result := expiring(subs, now, 7*24*time.Hour)
+
+	assert.Len(t, result, 1)
+	assert.Equal(t, 1, result[0].ID)
+}
+
+func TestExpiring_WindowBoundary(t *testing.T) {
+	now := time.Date(2025, 3, 25, 0, 0, 0, 0, time.UTC)
+	subs := []subscriptions.Subscription{subscription(1, "03-2025")}
+
+	assert.Len(t, expiring(subs, now, 7*24*time.Hour), 1, "expiry exactly at the window end is included")
+	assert.Empty(t, expiring(subs, now, 7*24*time.Hour-time.Second))
+}
+
+func TestSchedulerScan_NotifiesOnce(t *testing.T) {
+	source := &MockSource{Subscriptions: []subscriptions.Subscription{
+		subscription(1, "03-2025"),
+		subscription(2, "12-2025"),
+	}}
+	notifier := &MockNotifier{}
+	scheduler := NewScheduler(source, notifier, &MockLogger{}, time.Hour, 7*24*time.Hour)
+	scheduler.now = func() time.Time { return time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC) }
+
+	scheduler.scan(context.Background())
+	scheduler.scan(context.Background())
+
+	assert.Equal(t, []int{1}, notifier.Notified)
+}
+
+func TestSchedulerScan_RetriesFailedNotification(t *testing.T) {
+	source := &MockSource{Subscriptions: []subscriptions.Subscription{subscription(1, "03-2025")}}
+	notifier := &MockNotifier{Err: errors.New("webhook unavailable")}
+	scheduler := NewScheduler(source, notifier, &MockLogger{}, time.Hour, 7*24*time.Hour)
+	scheduler.now = func() time.Time { return time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC) }
+
+	scheduler.scan(context.Background())
+	notifier.Err = nil
+	scheduler.scan(context.Background())
+
+	assert.Equal(t, []int{1}, notifier.Notified)
+}
+
+func TestSchedulerRun_StopsOnCancel(t *testing.T) {
+	scheduler := NewScheduler(&MockSource{}, &MockNotifier{}, &MockLogger{}, time.Millisecond, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after context cancellation")
+	}
+}
+
+// shutdownNotifier cancels the run of the scheduler on its first reminder, as
+// a SIGTERM arriving mid-scan would, and then fails reminders sent with a done
+// context. Blocking makes it wait for the context instead, like a hanging
+// webhook.
+type shutdownNotifier struct {
+	cancel   context.CancelFunc
+	Blocking bool
+	Notified []int
+}
+
+func (n *shutdownNotifier) Notify(ctx context.Context, sub subscriptions.Subscription, expiresAt time.Time) error {
+	n.cancel()
+	if n.Blocking {
+		<-ctx.Done()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n.Notified = append(n.Notified, sub.ID)
+	return nil
+}
+
+func runUntilStopped(t *testing.T, ctx context.Context, scheduler *Scheduler) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after context cancellation")
+	}
+}
+
+func TestSchedulerRun_DrainsQueueOnShutdown(t *testing.T) {
+	source := &MockSource{Subscriptions: []subscriptions.Subscription{
+		subscription(1, "03-2025"),
+		subscription(2, "03-2025"),
+		subscription(3, "03-2025"),
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	notifier := &shutdownNotifier{cancel: cancel}
+	log := &MockLogger{}
+	scheduler := NewScheduler(source, notifier, log, time.Hour, 7*24*time.Hour)
+	scheduler.now = func() time.Time { return time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC) }
+
+	runUntilStopped(t, ctx, scheduler)
+
+	assert.Equal(t, []int{1, 2, 3}, notifier.Notified)
+	assert.Empty(t, scheduler.queue)
+	assert.Empty(t, log.Warnings)
+}
+
+func TestSchedulerRun_DropsQueueAfterDrainTimeout(t *testing.T) {
+	source := &MockSource{Subscriptions: []subscriptions.Subscription{
+		subscription(1, "03-2025"),
+		subscription(2, "03-2025"),
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	notifier := &shutdownNotifier{cancel: cancel, Blocking: true}
+	log := &MockLogger{}
+	scheduler := NewScheduler(source, notifier, log, time.Hour, 7*24*time.Hour, WithDrainTimeout(10*time.Millisecond))
+	scheduler.now = func() time.Time { return time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC) }
+
+	runUntilStopped(t, ctx, scheduler)
+
+	assert.Empty(t, notifier.Notified)
+	assert.Empty(t, scheduler.queue)
+	assert.Equal(t, []string{"Dropping undelivered reminders"}, log.Warnings)
+}
@@ -1,10 +1,18 @@
 package subscriptions
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -14,45 +22,331 @@ import (
 type Handler struct {
 	service SubscriptionService
 	log     logger.LoggerInterface
+
+	disabled     map[string]bool
+	middlewares  map[string][]func(http.Handler) http.Handler
+	adminAuth    func(http.Handler) http.Handler
+	registered   map[string]bool
+	strictDelete bool
+
+	defaultTimeout time.Duration
+	timeouts       map[string]time.Duration
+}
+
+type HandlerOption func(*Handler)
+
+// WithDisabledEndpoints turns off the named endpoints: they respond with 404.
+// Names: list, create, describe, validate, get, cost, cost-rolling, cost-query, cost-preview, services, subscribers,
+// users, diversity, budget-status, changes, meta, date-range, stats, summary, export, update, delete, renew, bulk-delete, bulk-cancel,
+// recompute-summaries.
+func WithDisabledEndpoints(names ...string) HandlerOption {
+	return func(h *Handler) {
+		for _, name := range names {
+			h.disabled[name] = true
+		}
+	}
+}
+
+// WithEndpointMiddleware wraps a single endpoint, named as in WithDisabledEndpoints,
+// with the given middlewares.
+func WithEndpointMiddleware(name string, middlewares ...func(http.Handler) http.Handler) HandlerOption {
+	return func(h *Handler) {
+		h.middlewares[name] = append(h.middlewares[name], middlewares...)
+	}
+}
+
+// adminEndpoints are the endpoints, named as in WithDisabledEndpoints, that
+// change the subscriptions of a whole user or table at once. They are served
+// only behind the auth of WithAdminAuth.
+var adminEndpoints = map[string]bool{
+	"bulk-delete":         true,
+	"bulk-cancel":         true,
+	"recompute-summaries": true,
+}
+
+// WithAdminAuth guards the admin endpoints with auth, such as
+// middleware.APIKey. Without it they answer 404, as if disabled.
+func WithAdminAuth(auth func(http.Handler) http.Handler) HandlerOption {
+	return func(h *Handler) {
+		h.adminAuth = auth
+	}
+}
+
+// WithStrictDelete makes deleting a missing subscription answer 404 instead of
+// the idempotent 204.
+func WithStrictDelete(strict bool) HandlerOption {
+	return func(h *Handler) {
+		h.strictDelete = strict
+	}
+}
+
+// WithEndpointTimeouts bounds how long endpoints may take: those named in
+// timeouts, as in WithDisabledEndpoints, get their own timeout and the others
+// defaultTimeout. A request running out of time is answered with 503 and its
+// context is cancelled. A zero timeout disables the limit.
+func WithEndpointTimeouts(defaultTimeout time.Duration, timeouts map[string]time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.defaultTimeout = defaultTimeout
+		h.timeouts = timeouts
+	}
 }
 
-func NewHandler(service SubscriptionService, log logger.LoggerInterface) *Handler {
-	return &Handler{service: service, log: log}
+func NewHandler(service SubscriptionService, log logger.LoggerInterface, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		service:     service,
+		log:         log,
+		disabled:    make(map[string]bool),
+		middlewares: make(map[string][]func(http.Handler) http.Handler),
+		registered:  make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/v1", func(r chi.Router) {
 		r.Route("/subscriptions", func(r chi.Router) {
-			r.Get("/", h.GetSubscriptions)
-			r.Post("/", h.CreateSubscription)
-			r.Get("/cost", h.GetCostByPeriod)
+			h.handle(r, "list", http.MethodGet, "/", h.GetSubscriptions)
+			h.handle(r, "create", http.MethodPost, "/", h.CreateSubscription)
+			h.handle(r, "describe", http.MethodOptions, "/", h.DescribeSubscriptions)
+			h.handle(r, "validate", http.MethodPost, "/validate", h.ValidateSubscription)
+			h.handle(r, "cost", http.MethodGet, "/cost", h.GetCostByPeriod)
+			h.handle(r, "cost-rolling", http.MethodGet, "/cost/rolling", h.GetRollingCost)
+			h.handle(r, "cost-query", http.MethodPost, "/cost/query", h.QueryCost)
+			h.handle(r, "cost-preview", http.MethodPost, "/cost/preview", h.PreviewCostChange)
+			h.handle(r, "services", http.MethodGet, "/services", h.GetServices)
+			h.handle(r, "subscribers", http.MethodGet, "/subscribers", h.GetSubscribers)
+			h.handle(r, "users", http.MethodGet, "/users", h.GetUsers)
+			h.handle(r, "diversity", http.MethodGet, "/diversity", h.GetServiceDiversity)
+			h.handle(r, "budget-status", http.MethodGet, "/budget-status", h.GetBudgetStatus)
+			h.handle(r, "changes", http.MethodGet, "/changes", h.GetChanges)
+			h.handle(r, "meta", http.MethodGet, "/meta", h.GetMeta)
+			h.handle(r, "date-range", http.MethodGet, "/date-range", h.GetDateRange)
+			h.handle(r, "stats", http.MethodGet, "/stats", h.GetStats)
+			h.handle(r, "summary", http.MethodGet, "/summary", h.GetUserSummary)
+			h.handle(r, "export", http.MethodGet, "/export", h.ExportSubscriptions)
 			r.Route("/{id}", func(r chi.Router) {
-				r.Patch("/", h.UpdateSubscription)
-				r.Delete("/", h.DeleteSubscription)
+				h.handle(r, "get", http.MethodGet, "/", h.GetSubscription)
+				h.handle(r, "update", http.MethodPatch, "/", h.UpdateSubscription)
+				h.handle(r, "delete", http.MethodDelete, "/", h.DeleteSubscription)
+				h.handle(r, "renew", http.MethodPost, "/renew", h.RenewSubscription)
 			})
 		})
+		r.Route("/users/{user_id}", func(r chi.Router) {
+			h.handle(r, "bulk-delete", http.MethodDelete, "/subscriptions", h.DeleteUserSubscriptions)
+			h.handle(r, "bulk-cancel", http.MethodPost, "/subscriptions/cancel", h.CancelUserSubscriptions)
+		})
+		h.handle(r, "recompute-summaries", http.MethodPost, "/admin/recompute-summaries", h.RecomputeSummaries)
+	})
+}
+
+// handle registers the route with its endpoint middlewares and timeout. A
+// disabled endpoint answers 404 instead, so it is not caught by a neighbouring
+// pattern such as /{id}.
+func (h *Handler) handle(r chi.Router, name, method, pattern string, handlerFn http.HandlerFunc) {
+	h.registered[name] = true
+
+	if h.disabled[name] {
+		h.log.Info("Endpoint disabled", map[string]any{"endpoint": name})
+		r.Method(method, pattern, http.NotFoundHandler())
+		return
+	}
+
+	middlewares := h.middlewares[name]
+	if adminEndpoints[name] {
+		if h.adminAuth == nil {
+			h.log.Info("Admin endpoint disabled: no admin auth configured", map[string]any{"endpoint": name})
+			r.Method(method, pattern, http.NotFoundHandler())
+			return
+		}
+		middlewares = append([]func(http.Handler) http.Handler{h.adminAuth}, middlewares...)
+	}
+
+	var handler http.Handler = handlerFn
+	if timeout := h.timeout(name); timeout > 0 {
+		handler = withTimeout(handler, timeout)
+	}
+	r.With(middlewares...).Method(method, pattern, handler)
+}
+
+// CheckEndpointNames returns an error listing the endpoint names given to
+// WithDisabledEndpoints, WithEndpointMiddleware or WithEndpointTimeouts that
+// RegisterRoutes did not register, such as a typo in the configuration. Call
+// it after RegisterRoutes.
+func (h *Handler) CheckEndpointNames() error {
+	var unknown []string
+	check := func(name string) {
+		if !h.registered[name] && !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	for name := range h.disabled {
+		check(name)
+	}
+	for name := range h.middlewares {
+		check(name)
+	}
+	for name := range h.timeouts {
+		check(name)
+	}
+
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("unknown endpoints: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// timeout returns the timeout of the named endpoint.
+func (h *Handler) timeout(name string) time.Duration {
+	if timeout, ok := h.timeouts[name]; ok {
+		return timeout
+	}
+	return h.defaultTimeout
+}
+
+// codeNotFound is the error code of a response to a missing subscription.
+const codeNotFound = "not_found"
+
+// timeoutBody is the response to a request that ran out of time.
+const timeoutBody = `{"status":"error","data":null,"error":"Request timed out"}`
+
+// withTimeout answers 503 with timeoutBody when next does not finish within
+// timeout. The response of next is buffered until it returns.
+func withTimeout(next http.Handler, timeout time.Duration) http.Handler {
+	timeoutHandler := http.TimeoutHandler(next, timeout, timeoutBody)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeoutHandler.ServeHTTP(timeoutWriter{w}, r)
 	})
 }
 
+// timeoutWriter labels the timeoutBody written by http.TimeoutHandler as JSON.
+// Responses of the wrapped handler carry their own headers.
+type timeoutWriter struct {
+	http.ResponseWriter
+}
+
+func (w timeoutWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
 // GetSubscriptions godoc
 //
 //	@Summary		Get all subscriptions
-//	@Description	Retrieve all subscriptions
+//	@Description	Retrieve all subscriptions, newest first unless sort_by is given. With external_id only the subscription with that external ID is listed. Listings over the hard cap are cut short and flagged with truncated.
 //	@Tags			subscriptions
 //	@Produce		json
-//	@Success		200	{object}	Response
+//	@Param			sort_by		query		string	false	"Sort field"	Enums(id, service_name, price, start_date, end_date, created_at, updated_at)
+//	@Param			order		query		string	false	"Sort order"	Enums(asc, desc)
+//	@Param			external_id	query		string	false	"External ID"
+//	@Success		200			{object}	Response
+//	@Failure		400			{object}	Response
 //	@Router			/subscriptions [get]
 func (h *Handler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
 	h.log.Info("GET /subscriptions", nil)
 
-	subs, err := h.service.GetAllSubscriptions(r.Context())
+	if externalID := r.URL.Query().Get("external_id"); externalID != "" {
+		h.getSubscriptionByExternalID(w, r, externalID)
+		return
+	}
+
+	sort := Sort{Field: r.URL.Query().Get("sort_by"), Order: r.URL.Query().Get("order")}
+
+	list, err := h.service.ListSubscriptions(r.Context(), sort)
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		h.log.Error("Invalid sort parameters", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to fetch subscriptions", map[string]any{"error": err})
 		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to fetch subscriptions"})
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: subs})
+	if list.Truncated {
+		h.writeJSON(w, http.StatusOK, Response{
+			Status:    "success",
+			Data:      list.Subscriptions,
+			Truncated: true,
+			Warning:   fmt.Sprintf("only the first %d subscriptions are listed, use filters or the export endpoint to get all of them", len(list.Subscriptions)),
+		})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: list.Subscriptions})
+}
+
+// getSubscriptionByExternalID lists the subscription with the external ID, or
+// nothing if there is none.
+func (h *Handler) getSubscriptionByExternalID(w http.ResponseWriter, r *http.Request, externalID string) {
+	sub, err := h.service.GetSubscriptionByExternalID(r.Context(), externalID)
+	if errors.Is(err, ErrNotFound) {
+		h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: []Subscription{}})
+		return
+	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch subscription by external ID", map[string]any{"error": err, "external_id": externalID})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to fetch subscriptions"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: []Subscription{*sub}})
+}
+
+// DescribeSubscriptions godoc
+//
+//	@Summary		Describe the subscriptions collection
+//	@Description	List the supported operations and their parameters. The Allow header lists the methods.
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Success		200	{object}	Response{data=[]OperationDescription}
+//	@Header			200	{string}	Allow	"Supported methods"
+//	@Router			/subscriptions [options]
+func (h *Handler) DescribeSubscriptions(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("OPTIONS /subscriptions", nil)
+
+	var operations []OperationDescription
+	if !h.disabled["list"] {
+		operations = append(operations, OperationDescription{
+			Method:      http.MethodGet,
+			Description: "List subscriptions",
+			Parameters:  []string{"sort_by", "order", "external_id"},
+		})
+	}
+	if !h.disabled["create"] {
+		operations = append(operations, OperationDescription{
+			Method:      http.MethodPost,
+			Description: "Create a subscription",
+			Parameters:  []string{"service_name", "price", "user_id", "start_date", "end_date", "external_id"},
+		})
+	}
+	operations = append(operations, OperationDescription{
+		Method:      http.MethodOptions,
+		Description: "Describe the supported operations",
+	})
+
+	methods := make([]string, 0, len(operations))
+	for _, op := range operations {
+		methods = append(methods, op.Method)
+	}
+
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: operations})
 }
 
 // CreateSubscription godoc
@@ -62,9 +356,14 @@ func (h *Handler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
 //	@Tags			subscriptions
 //	@Accept			json
 //	@Produce		json
-//	@Param			request	body		CreateSubscriptionRequest	true	"Subscription data"
-//	@Success		201		{object}	Response
-//	@Failure		400		{object}	Response
+//	@Param			request			body		CreateSubscriptionRequest	true	"Subscription data"
+//	@Param			If-None-Match	header		string						false	"* to create only if no subscription has the external_id, answering 412 otherwise"
+//	@Success		201				{object}	Response
+//	@Header			201				{string}	Location	"URL of the created subscription"
+//	@Failure		400				{object}	Response
+//	@Failure		409				{object}	Response	"external_id is already used"
+//	@Failure		412				{object}	Response	"external_id is already used and If-None-Match is *"
+//	@Failure		422				{object}	Response	"start_date is before the current month in strict mode"
 //	@Router			/subscriptions [post]
 func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	h.log.Info("POST /subscriptions", nil)
@@ -76,6 +375,12 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(bytes.TrimSpace(body)) == 0 {
+		h.log.Error("Empty request body", nil)
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "request body is empty"})
+		return
+	}
+
 	var req CreateSubscriptionRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		h.log.Error("Invalid JSON", map[string]any{"error": err})
@@ -83,7 +388,35 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// If-None-Match: * makes the create conditional on the external_id being
+	// unused, the unique index deciding it without a separate lookup.
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch != "" && ifNoneMatch != "*" {
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "If-None-Match must be *"})
+		return
+	}
+	if ifNoneMatch == "*" && (req.ExternalID == nil || *req.ExternalID == "") {
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "If-None-Match requires external_id"})
+		return
+	}
+
 	sub, err := h.service.CreateSubscription(r.Context(), req)
+	if errors.Is(err, ErrExternalIDExists) && ifNoneMatch == "*" {
+		h.writeJSON(w, http.StatusPreconditionFailed, Response{Status: "error", Error: err.Error()})
+		return
+	}
+	if errors.Is(err, ErrExternalIDExists) {
+		h.writeJSON(w, http.StatusConflict, Response{Status: "error", Error: err.Error()})
+		return
+	}
+	if errors.Is(err, ErrPastStartDate) {
+		h.writeJSON(w, http.StatusUnprocessableEntity, Response{Status: "error", Error: err.Error()})
+		return
+	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to create subscription", map[string]any{"error": err})
 		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
@@ -91,13 +424,80 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.log.Info("Subscription created successfully", map[string]any{"id": sub.ID})
+	w.Header().Set("Location", subscriptionLocation(sub.ID))
 	h.writeJSON(w, http.StatusCreated, Response{Status: "success", Data: sub})
 }
 
+// ValidateSubscription godoc
+//
+//	@Summary		Validate a subscription payload
+//	@Description	Check a subscription payload as create would, without saving it. Every violation is reported.
+//	@Tags			subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CreateSubscriptionRequest	true	"Subscription data"
+//	@Success		200		{object}	Response{data=ValidationResponse}
+//	@Failure		400		{object}	Response
+//	@Router			/subscriptions/validate [post]
+func (h *Handler) ValidateSubscription(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("POST /subscriptions/validate", nil)
+
+	var req CreateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("Invalid JSON", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid JSON"})
+		return
+	}
+
+	violations := h.service.ValidateSubscription(r.Context(), req)
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: ValidationResponse{Valid: len(violations) == 0, Errors: violations}})
+}
+
+// GetSubscription godoc
+//
+//	@Summary		Get a subscription
+//	@Description	Retrieve a subscription by ID
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			id	path		int	true	"Subscription ID"
+//	@Success		200	{object}	Response{data=Subscription}
+//	@Failure		400	{object}	Response
+//	@Failure		404	{object}	Response
+//	@Failure		500	{object}	Response
+//	@Router			/subscriptions/{id} [get]
+func (h *Handler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.log.Error("Invalid subscription ID", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid subscription ID"})
+		return
+	}
+
+	h.log.Info("GET /subscriptions/{id}", map[string]any{"id": id})
+
+	sub, err := h.service.GetSubscriptionByID(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		// err may wrap database errors; only its sentinel is shown.
+		h.writeJSON(w, http.StatusNotFound, Response{Status: "error", Code: codeNotFound, Error: ErrNotFound.Error()})
+		return
+	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch subscription", map[string]any{"error": err, "id": id})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to fetch subscription"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: sub})
+}
+
 // UpdateSubscription godoc
 //
 //	@Summary		Update a subscription
-//	@Description	Update an existing subscription
+//	@Description	Update an existing subscription. The owner (user_id) cannot be changed.
 //	@Tags			subscriptions
 //	@Accept			json
 //	@Produce		json
@@ -106,6 +506,8 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 //	@Success		200		{object}	Response
 //	@Failure		400		{object}	Response
 //	@Failure		404		{object}	Response
+//	@Failure		409		{object}	Response	"external_id is already used"
+//	@Failure		422		{object}	Response
 //	@Router			/subscriptions/{id} [patch]
 func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(chi.URLParam(r, "id"))
@@ -124,6 +526,12 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(bytes.TrimSpace(body)) == 0 {
+		h.log.Error("Empty request body", nil)
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "request body is empty"})
+		return
+	}
+
 	var req UpdateSubscriptionRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		h.log.Error("Invalid JSON", map[string]any{"error": err})
@@ -132,6 +540,18 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sub, err := h.service.UpdateSubscription(r.Context(), id, req)
+	if errors.Is(err, ErrUserIDImmutable) {
+		h.writeJSON(w, http.StatusUnprocessableEntity, Response{Status: "error", Error: err.Error()})
+		return
+	}
+	if errors.Is(err, ErrExternalIDExists) {
+		h.writeJSON(w, http.StatusConflict, Response{Status: "error", Error: err.Error()})
+		return
+	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to update subscription", map[string]any{"error": err, "id": id})
 		h.writeJSON(w, http.StatusNotFound, Response{Status: "error", Error: err.Error()})
@@ -145,12 +565,14 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 // DeleteSubscription godoc
 //
 //	@Summary		Delete a subscription
-//	@Description	Delete an existing subscription
+//	@Description	Delete a subscription. Deleting a missing subscription also succeeds, unless strict delete mode is enabled.
 //	@Tags			subscriptions
 //	@Produce		json
-//	@Param			id	path		int	true	"Subscription ID"
-//	@Success		200	{object}	Response
-//	@Failure		404	{object}	Response
+//	@Param			id	path	int	true	"Subscription ID"
+//	@Success		204
+//	@Failure		400	{object}	Response
+//	@Failure		404	{object}	Response	"Strict delete mode only"
+//	@Failure		500	{object}	Response
 //	@Router			/subscriptions/{id} [delete]
 func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(chi.URLParam(r, "id"))
@@ -162,15 +584,157 @@ func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 
 	h.log.Info("DELETE /subscriptions/{id}", map[string]any{"id": id})
 
-	err = h.service.DeleteSubscription(r.Context(), id)
+	sub, err := h.service.DeleteSubscription(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		if h.strictDelete {
+			h.writeJSON(w, http.StatusNotFound, Response{Status: "error", Error: err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to delete subscription", map[string]any{"error": err, "id": id})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to delete subscription"})
+		return
+	}
+
+	h.log.Info("Subscription deleted successfully", map[string]any{
+		"id":           sub.ID,
+		"service_name": sub.ServiceName,
+		"price":        sub.Price,
+		"user_id":      sub.UserID,
+		"start_date":   sub.StartDate,
+		"end_date":     sub.EndDate,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RenewSubscription godoc
+//
+//	@Summary		Renew a subscription
+//	@Description	Create a new subscription for a new period, copying service, price and user from the original and linking it via renewed_from_id
+//	@Tags			subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"Subscription ID"
+//	@Param			request	body		RenewSubscriptionRequest	true	"Renewal period"
+//	@Success		201		{object}	Response{data=Subscription}
+//	@Header			201		{string}	Location	"URL of the created subscription"
+//	@Failure		400		{object}	Response
+//	@Failure		404		{object}	Response
+//	@Router			/subscriptions/{id}/renew [post]
+func (h *Handler) RenewSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.log.Error("Invalid subscription ID", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid subscription ID"})
+		return
+	}
+
+	h.log.Info("POST /subscriptions/{id}/renew", map[string]any{"id": id})
+
+	var req RenewSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("Invalid JSON", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid JSON"})
+		return
+	}
+
+	sub, err := h.service.RenewSubscription(r.Context(), id, req)
+	if errors.Is(err, ErrNotFound) {
 		h.writeJSON(w, http.StatusNotFound, Response{Status: "error", Error: err.Error()})
 		return
 	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to renew subscription", map[string]any{"error": err, "id": id})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.log.Info("Subscription renewed successfully", map[string]any{"id": sub.ID, "renewed_from_id": id})
+	w.Header().Set("Location", subscriptionLocation(sub.ID))
+	h.writeJSON(w, http.StatusCreated, Response{Status: "success", Data: sub})
+}
+
+// DeleteUserSubscriptions godoc
+//
+//	@Summary		Delete all subscriptions of a user
+//	@Description	Permanently delete every subscription of a user (data erasure request)
+//	@Tags			users
+//	@Produce		json
+//	@Security		AdminAPIKey
+//	@Param			user_id	path		string	true	"User ID (UUID)"
+//	@Success		200		{object}	Response{data=DeleteUserSubscriptionsResponse}
+//	@Failure		400		{object}	Response
+//	@Failure		401		{object}	Response
+//	@Failure		500		{object}	Response
+//	@Router			/users/{user_id}/subscriptions [delete]
+func (h *Handler) DeleteUserSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	h.log.Info("DELETE /users/{user_id}/subscriptions", map[string]any{"user_id": userID})
+
+	deleted, err := h.service.DeleteUserSubscriptions(r.Context(), userID)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to delete user subscriptions", map[string]any{"error": err, "user_id": userID})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to delete user subscriptions"})
+		return
+	}
+
+	h.log.Info("User subscriptions deleted successfully", map[string]any{"user_id": userID, "count": deleted})
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: DeleteUserSubscriptionsResponse{Deleted: deleted}})
+}
+
+// CancelUserSubscriptions godoc
+//
+//	@Summary		Cancel all subscriptions of a user
+//	@Description	End every subscription of a user active in the current month with the current month, e.g. when the account is closed. Subscriptions already ending by then or starting later are left untouched.
+//	@Tags			users
+//	@Produce		json
+//	@Security		AdminAPIKey
+//	@Param			user_id	path		string	true	"User ID (UUID)"
+//	@Success		200		{object}	Response{data=CancelUserSubscriptionsResponse}
+//	@Failure		400		{object}	Response
+//	@Failure		401		{object}	Response
+//	@Failure		500		{object}	Response
+//	@Router			/users/{user_id}/subscriptions/cancel [post]
+func (h *Handler) CancelUserSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	h.log.Info("POST /users/{user_id}/subscriptions/cancel", map[string]any{"user_id": userID})
+
+	cancelled, err := h.service.CancelUserSubscriptions(r.Context(), userID)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to cancel user subscriptions", map[string]any{"error": err, "user_id": userID})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to cancel user subscriptions"})
+		return
+	}
 
-	h.log.Info("Subscription deleted successfully", map[string]any{"id": id})
-	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: map[string]string{"message": "Subscription deleted"}})
+	h.log.Info("User subscriptions cancelled successfully", map[string]any{"user_id": userID, "count": cancelled})
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: CancelUserSubscriptionsResponse{Cancelled: cancelled}})
 }
 
 // GetCostByPeriod godoc
@@ -179,48 +743,820 @@ func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 //	@Description	Calculate total cost of subscriptions for a given period with optional filters
 //	@Tags			subscriptions
 //	@Produce		json
-//	@Param			start_date		query		string	true	"Start date (MM-YYYY format)"
-//	@Param			end_date		query		string	false	"End date (MM-YYYY format)"
-//	@Param			user_id			query		string	false	"User ID (UUID)"
-//	@Param			service_name	query		string	false	"Service name"
-//	@Success		200				{object}	Response
-//	@Failure		400				{object}	Response
+//	@Param			start_date			query		string	false	"Start date (MM-YYYY format or now for the current month), required unless year is set"
+//	@Param			end_date			query		string	false	"End date (MM-YYYY format or now for the current month)"
+//	@Param			year				query		string	false	"Whole year (YYYY), shorthand for start_date=01-YYYY and end_date=12-YYYY"
+//	@Param			user_id				query		string	false	"User ID (UUID)"
+//	@Param			service_name		query		string	false	"Service name"
+//	@Param			include_ids			query		bool	false	"List the IDs of the included subscriptions in subscription_ids"
+//	@Param			If-None-Match		header		string	false	"ETag of a previous response: return 304 if matching subscriptions have not changed since"
+//	@Param			If-Modified-Since	header		string	false	"Return 304 if no matching subscription was created or updated since this time; ignored with If-None-Match"
+//	@Success		200					{object}	Response
+//	@Success		304					"Not Modified"
+//	@Failure		400					{object}	Response
 //	@Router			/subscriptions/cost [get]
 func (h *Handler) GetCostByPeriod(w http.ResponseWriter, r *http.Request) {
 	h.log.Info("GET /subscriptions/cost", nil)
 
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
-	userIDStr := r.URL.Query().Get("user_id")
-	serviceName := r.URL.Query().Get("service_name")
+	filter, err := parseFilter(r)
+	if err != nil {
+		h.log.Error("Invalid user ID format", map[string]any{"error": err, "user_id": r.URL.Query().Get("user_id")})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid user ID format"})
+		return
+	}
 
-	var userID *uuid.UUID
-	if userIDStr != "" {
-		uid, err := uuid.Parse(userIDStr)
-		if err != nil {
-			h.log.Error("Invalid user ID format", map[string]any{"error": err, "user_id": userIDStr})
-			h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid user ID format"})
+	if year := r.URL.Query().Get("year"); year != "" {
+		if filter.StartDate != "" || filter.EndDate != "" {
+			h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "year cannot be combined with start_date or end_date"})
 			return
 		}
-		userID = &uid
+		if !isYear(year) {
+			h.log.Error("Invalid year", map[string]any{"year": year})
+			h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "year must be a four-digit year"})
+			return
+		}
+		filter.StartDate = "01-" + year
+		filter.EndDate = "12-" + year
 	}
 
-	var serviceNamePtr *string
-	if serviceName != "" {
-		serviceNamePtr = &serviceName
+	includeIDs := false
+	if includeIDsStr := r.URL.Query().Get("include_ids"); includeIDsStr != "" {
+		if includeIDs, err = strconv.ParseBool(includeIDsStr); err != nil {
+			h.log.Error("Invalid include_ids", map[string]any{"error": err})
+			h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid include_ids"})
+			return
+		}
 	}
 
-	cost, err := h.service.GetCostByPeriod(r.Context(), startDate, endDate, userID, serviceNamePtr)
+	version, err := h.service.GetCostVersion(r.Context(), filter.StartDate, filter.EndDate, filter.UserID, filter.ServiceName)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to calculate cost", map[string]any{"error": err})
 		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
 		return
 	}
 
-	h.log.Info("Cost calculated successfully", map[string]any{"total": cost.TotalCost, "count": cost.Count})
+	// MAX(updated_at) alone does not move on delete, hence an ETag also
+	// covering the count. If-Modified-Since is only consulted without
+	// If-None-Match, as clients able to send the ETag should.
+	etag := version.ETag()
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag)
+	var modified time.Time
+	if version.LastModified != nil {
+		modified = version.LastModified.UTC().Truncate(time.Second)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	}
+
+	notModified := false
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		notModified = etagMatch(ifNoneMatch, etag)
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && version.LastModified != nil {
+		notModified = !modified.After(since)
+	}
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if includeIDs {
+		cost, err := h.service.GetCostWithIDs(r.Context(), filter.StartDate, filter.EndDate, filter.UserID, filter.ServiceName)
+		if errors.Is(err, ErrUnavailable) {
+			h.writeUnavailable(w)
+			return
+		}
+		if err != nil {
+			h.log.Error("Failed to calculate cost", map[string]any{"error": err})
+			h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+			return
+		}
+
+		h.log.Info("Cost calculated successfully", map[string]any{"total": cost.TotalCost, "count": cost.Count})
+		h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: cost})
+		return
+	}
+
+	cost, err := h.service.GetCostByPeriod(r.Context(), filter.StartDate, filter.EndDate, filter.UserID, filter.ServiceName)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to calculate cost", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.log.Info("Cost calculated successfully", map[string]any{"total": cost.TotalCost, "count": cost.Count})
 	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: cost})
 }
 
+// QueryCost godoc
+//
+//	@Summary		Get subscriptions cost for a filter body
+//	@Description	Calculate total cost of subscriptions like GET /subscriptions/cost, taking the filter as a JSON body with lists of users and services that do not fit a query string. Empty lists are not applied.
+//	@Tags			subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CostQuery	true	"Cost filter"
+//	@Success		200		{object}	Response{data=CostWithIDsResponse}	"subscription_ids only with include_ids"
+//	@Failure		400		{object}	Response
+//	@Router			/subscriptions/cost/query [post]
+func (h *Handler) QueryCost(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("POST /subscriptions/cost/query", nil)
+
+	var query CostQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		h.log.Error("Invalid JSON", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid JSON"})
+		return
+	}
+
+	cost, err := h.service.GetCostByQuery(r.Context(), query)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to calculate cost", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.log.Info("Cost calculated successfully", map[string]any{"total": cost.TotalCost, "count": cost.Count})
+	if query.IncludeIDs {
+		h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: cost})
+		return
+	}
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: cost.CostResponse})
+}
+
+// PreviewCostChange godoc
+//
+//	@Summary		Preview the cost of a price change
+//	@Description	Calculate total cost of subscriptions like POST /subscriptions/cost/query, and the total if the subscriptions to a service among them had another price. Nothing is changed.
+//	@Tags			subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CostPreviewRequest	true	"Cost filter and price change"
+//	@Success		200		{object}	Response{data=CostPreviewResponse}
+//	@Failure		400		{object}	Response
+//	@Router			/subscriptions/cost/preview [post]
+func (h *Handler) PreviewCostChange(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("POST /subscriptions/cost/preview", nil)
+
+	var req CostPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("Invalid JSON", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid JSON"})
+		return
+	}
+
+	preview, err := h.service.PreviewCostChange(r.Context(), req)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to preview cost", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.log.Info("Cost preview calculated successfully", map[string]any{"total": preview.TotalCost, "delta": preview.Delta})
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: preview})
+}
+
+// GetRollingCost godoc
+//
+//	@Summary		Get subscriptions cost for the last months
+//	@Description	Calculate total cost of subscriptions for a window of N months ending with the current month
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			months	query		int		true	"Window size in months (1-120)"
+//	@Param			user_id	query		string	false	"User ID (UUID)"
+//	@Success		200		{object}	Response{data=CostResponse}
+//	@Failure		400		{object}	Response
+//	@Router			/subscriptions/cost/rolling [get]
+func (h *Handler) GetRollingCost(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/cost/rolling", nil)
+
+	months, err := queryInt(r, "months")
+	if err != nil {
+		h.log.Error("Invalid months", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid months"})
+		return
+	}
+
+	var userID *uuid.UUID
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		uid, err := uuid.Parse(userIDStr)
+		if err != nil {
+			h.log.Error("Invalid user ID format", map[string]any{"error": err, "user_id": userIDStr})
+			h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid user ID format"})
+			return
+		}
+		userID = &uid
+	}
+
+	cost, err := h.service.GetRollingCost(r.Context(), months, userID)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to calculate rolling cost", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: cost})
+}
+
+// GetServices godoc
+//
+//	@Summary		Get distinct services
+//	@Description	Retrieve a paginated list of distinct service names, optionally filtered by prefix or searched by q. Search results are ranked exact match first, then prefix matches, then substring matches.
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			prefix	query		string	false	"Service name prefix (case-insensitive)"
+//	@Param			q		query		string	false	"Text contained in the service name (case-insensitive)"
+//	@Param			limit	query		int		false	"Page size (1-100, default 20)"
+//	@Param			offset	query		int		false	"Number of items to skip"
+//	@Success		200		{object}	Response{data=Page[string]}
+//	@Failure		400		{object}	Response
+//	@Router			/subscriptions/services [get]
+func (h *Handler) GetServices(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/services", nil)
+
+	filter := ServiceFilter{
+		Prefix: r.URL.Query().Get("prefix"),
+		Query:  r.URL.Query().Get("q"),
+	}
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		h.log.Error("Invalid limit", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid limit"})
+		return
+	}
+
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		h.log.Error("Invalid offset", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid offset"})
+		return
+	}
+
+	page, err := h.service.GetServices(r.Context(), filter, limit, offset)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch services", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: page})
+}
+
+// GetSubscribers godoc
+//
+//	@Summary		Get subscribers of a service
+//	@Description	Retrieve a paginated list of distinct users with an active subscription to the service
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			service_name	query		string	true	"Service name"
+//	@Param			limit			query		int		false	"Page size (1-100, default 20)"
+//	@Param			offset			query		int		false	"Number of items to skip"
+//	@Success		200				{object}	Response{data=Page[string]}
+//	@Failure		400				{object}	Response
+//	@Router			/subscriptions/subscribers [get]
+func (h *Handler) GetSubscribers(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/subscribers", nil)
+
+	serviceName := r.URL.Query().Get("service_name")
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		h.log.Error("Invalid limit", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid limit"})
+		return
+	}
+
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		h.log.Error("Invalid offset", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid offset"})
+		return
+	}
+
+	page, err := h.service.GetSubscribers(r.Context(), serviceName, limit, offset)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch subscribers", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: page})
+}
+
+// GetUsers godoc
+//
+//	@Summary		Get users with subscriptions
+//	@Description	Retrieve a paginated list of distinct users with the number of their subscriptions
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			limit	query		int		false	"Page size (1-100, default 20)"
+//	@Param			offset	query		int		false	"Number of items to skip"
+//	@Success		200		{object}	Response{data=Page[UserSubscriptions]}
+//	@Failure		400		{object}	Response
+//	@Router			/subscriptions/users [get]
+func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/users", nil)
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		h.log.Error("Invalid limit", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid limit"})
+		return
+	}
+
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		h.log.Error("Invalid offset", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid offset"})
+		return
+	}
+
+	page, err := h.service.GetUsers(r.Context(), limit, offset)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch users", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: page})
+}
+
+// GetServiceDiversity godoc
+//
+//	@Summary		Get distinct services per user
+//	@Description	Retrieve a paginated list of distinct users with the number of distinct services they subscribe to
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			limit	query		int		false	"Page size (1-100, default 20)"
+//	@Param			offset	query		int		false	"Number of items to skip"
+//	@Success		200		{object}	Response{data=Page[UserServices]}
+//	@Failure		400		{object}	Response
+//	@Router			/subscriptions/diversity [get]
+func (h *Handler) GetServiceDiversity(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/diversity", nil)
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		h.log.Error("Invalid limit", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid limit"})
+		return
+	}
+
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		h.log.Error("Invalid offset", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid offset"})
+		return
+	}
+
+	page, err := h.service.GetServiceDiversity(r.Context(), limit, offset)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch service diversity", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: page})
+}
+
+// GetBudgetStatus godoc
+//
+//	@Summary		Get budget status
+//	@Description	Compare the current monthly cost of every budgeted service with its monthly cap, reporting the services over budget and by how much
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Success		200	{object}	Response{data=[]BudgetStatus}
+//	@Failure		400	{object}	Response
+//	@Router			/subscriptions/budget-status [get]
+func (h *Handler) GetBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/budget-status", nil)
+
+	budgets, err := h.service.GetBudgetStatus(r.Context())
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch budget status", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: budgets})
+}
+
+// GetChanges godoc
+//
+//	@Summary		Get subscriptions changed since a time
+//	@Description	Retrieve subscriptions created or updated after since, oldest change first, for incremental sync. Deletions are not reported.
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			since	query		string	true	"Cutoff time (RFC 3339), exclusive"
+//	@Success		200		{object}	Response{data=[]Subscription}
+//	@Failure		400		{object}	Response
+//	@Failure		500		{object}	Response
+//	@Router			/subscriptions/changes [get]
+func (h *Handler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/changes", nil)
+
+	subs, err := h.service.GetChangesSince(r.Context(), r.URL.Query().Get("since"))
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		h.log.Error("Invalid since", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch changed subscriptions", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to fetch subscriptions"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: subs})
+}
+
+// GetMeta godoc
+//
+//	@Summary		Get accepted values
+//	@Description	Retrieve the values the server accepts under its current configuration: date format, service name pattern, sort fields and orders, export formats and limits.
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Success		200	{object}	Response{data=MetaResponse}
+//	@Router			/subscriptions/meta [get]
+func (h *Handler) GetMeta(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/meta", nil)
+
+	meta := h.service.GetMeta(r.Context())
+	meta.ExportFormats = exportFormats
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: meta})
+}
+
+// GetDateRange godoc
+//
+//	@Summary		Get subscription date bounds
+//	@Description	Retrieve the earliest start date and the latest end date across subscriptions
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			user_id	query		string	false	"User ID (UUID)"
+//	@Success		200		{object}	Response{data=DateRangeResponse}
+//	@Failure		400		{object}	Response
+//	@Failure		500		{object}	Response
+//	@Router			/subscriptions/date-range [get]
+func (h *Handler) GetDateRange(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/date-range", nil)
+
+	var userID *uuid.UUID
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		uid, err := uuid.Parse(userIDStr)
+		if err != nil {
+			h.log.Error("Invalid user ID format", map[string]any{"error": err, "user_id": userIDStr})
+			h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid user ID format"})
+			return
+		}
+		userID = &uid
+	}
+
+	dateRange, err := h.service.GetDateRange(r.Context(), userID)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch date range", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to fetch date range"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: dateRange})
+}
+
+// GetStats godoc
+//
+//	@Summary		Get subscription stats
+//	@Description	Aggregates over all subscriptions. When periodic refresh is enabled the latest snapshot is served; computed_at tells its age.
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			fresh	query		bool	false	"Recompute instead of serving the cached snapshot"
+//	@Success		200		{object}	Response{data=StatsResponse}
+//	@Failure		400		{object}	Response
+//	@Failure		500		{object}	Response
+//	@Router			/subscriptions/stats [get]
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/stats", nil)
+
+	fresh := false
+	if freshStr := r.URL.Query().Get("fresh"); freshStr != "" {
+		var err error
+		if fresh, err = strconv.ParseBool(freshStr); err != nil {
+			h.log.Error("Invalid fresh", map[string]any{"error": err})
+			h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid fresh"})
+			return
+		}
+	}
+
+	stats, err := h.service.GetStats(r.Context(), fresh)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch stats", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to fetch stats"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: stats})
+}
+
+// GetUserSummary godoc
+//
+//	@Summary		Get a user's cost summary
+//	@Description	Serve the monthly cost and number of active subscriptions of a user from the precomputed summaries. They are as old as computed_at, see POST /admin/recompute-summaries; use the cost endpoint for an exact figure.
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			user_id	query		string	true	"User ID (UUID)"
+//	@Success		200		{object}	Response{data=UserCostSummary}
+//	@Failure		400		{object}	Response
+//	@Failure		404		{object}	Response	"No active subscriptions at the last recompute"
+//	@Failure		500		{object}	Response
+//	@Router			/subscriptions/summary [get]
+func (h *Handler) GetUserSummary(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/summary", nil)
+
+	userIDStr := r.URL.Query().Get("user_id")
+	if userIDStr == "" {
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "user_id is required"})
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.log.Error("Invalid user ID format", map[string]any{"error": err, "user_id": userIDStr})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid user ID format"})
+		return
+	}
+
+	summary, err := h.service.GetUserSummary(r.Context(), userID)
+	if errors.Is(err, ErrSummaryNotFound) {
+		h.writeJSON(w, http.StatusNotFound, Response{Status: "error", Error: err.Error()})
+		return
+	}
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch summary", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to fetch summary"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: summary})
+}
+
+// RecomputeSummaries godoc
+//
+//	@Summary		Recompute user cost summaries
+//	@Description	Recompute the per-user cost summaries served by GET /subscriptions/summary from the subscriptions active in the current month
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminAPIKey
+//	@Success		200	{object}	Response{data=RecomputeSummariesResponse}
+//	@Failure		401	{object}	Response
+//	@Failure		500	{object}	Response
+//	@Router			/admin/recompute-summaries [post]
+func (h *Handler) RecomputeSummaries(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("POST /admin/recompute-summaries", nil)
+
+	result, err := h.service.RecomputeSummaries(r.Context())
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to recompute summaries", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Error: "Failed to recompute summaries"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, Response{Status: "success", Data: result})
+}
+
+// exportFormats are the accepted format values of the export endpoint.
+var exportFormats = []string{"csv", "jsonl", "table"}
+
+// ExportSubscriptions godoc
+//
+//	@Summary		Export subscriptions
+//	@Description	Export subscriptions as CSV, JSON Lines or an aligned text table, filtered like the cost endpoint
+//	@Tags			subscriptions
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Produce		text/plain
+//	@Param			format			query		string	false	"Export format: csv (default), jsonl or table"
+//	@Param			start_date		query		string	false	"Start date (MM-YYYY format)"
+//	@Param			end_date		query		string	false	"End date (MM-YYYY format)"
+//	@Param			user_id			query		string	false	"User ID (UUID)"
+//	@Param			service_name	query		string	false	"Service name"
+//	@Success		200				{string}	string
+//	@Failure		400				{object}	Response
+//	@Router			/subscriptions/export [get]
+func (h *Handler) ExportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	h.log.Info("GET /subscriptions/export", nil)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if !slices.Contains(exportFormats, format) {
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "format must be csv, jsonl or table"})
+		return
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		h.log.Error("Invalid user ID format", map[string]any{"error": err, "user_id": r.URL.Query().Get("user_id")})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid user ID format"})
+		return
+	}
+
+	subs, err := h.service.ExportSubscriptions(r.Context(), filter)
+	if errors.Is(err, ErrUnavailable) {
+		h.writeUnavailable(w)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to export subscriptions", map[string]any{"error": err})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: err.Error()})
+		return
+	}
+
+	if format == "jsonl" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="subscriptions.jsonl"`)
+		encoder := json.NewEncoder(w)
+		for _, sub := range subs {
+			if err := encoder.Encode(sub); err != nil {
+				h.log.Error("Failed to write export", map[string]any{"error": err})
+				return
+			}
+		}
+		return
+	}
+
+	if format == "table" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(writer, strings.Join(flatRecordHeader, "\t"))
+		for _, sub := range subs {
+			_, _ = fmt.Fprintln(writer, strings.Join(sub.toFlatRecord(), "\t"))
+		}
+		if err := writer.Flush(); err != nil {
+			h.log.Error("Failed to write export", map[string]any{"error": err})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="subscriptions.csv"`)
+	writer := csv.NewWriter(w)
+	_ = writer.Write(flatRecordHeader)
+	for _, sub := range subs {
+		_ = writer.Write(sub.toFlatRecord())
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.log.Error("Failed to write export", map[string]any{"error": err})
+	}
+}
+
+// isYear reports whether s is a four-digit year such as 2025.
+func isYear(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFilter reads the start_date, end_date, user_id and service_name query
+// parameters shared by the cost and export endpoints.
+func parseFilter(r *http.Request) (SubscriptionFilter, error) {
+	query := r.URL.Query()
+	filter := SubscriptionFilter{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+	}
+
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		uid, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return SubscriptionFilter{}, err
+		}
+		filter.UserID = &uid
+	}
+
+	if serviceName := query.Get("service_name"); serviceName != "" {
+		filter.ServiceName = &serviceName
+	}
+
+	return filter, nil
+}
+
+// pathUserID parses the user_id path parameter of the user endpoints. A
+// malformed one is answered with 400 and the error of a malformed user_id query
+// parameter, and ok is false.
+func (h *Handler) pathUserID(w http.ResponseWriter, r *http.Request) (_ uuid.UUID, ok bool) {
+	userIDStr := chi.URLParam(r, "user_id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.log.Error("Invalid user ID format", map[string]any{"error": err, "user_id": userIDStr})
+		h.writeJSON(w, http.StatusBadRequest, Response{Status: "error", Error: "Invalid user ID format"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// etagMatch reports whether the If-None-Match header lists etag, compared
+// weakly as RFC 9110 requires for GET.
+func etagMatch(header, etag string) bool {
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionLocation returns the URL path of a subscription.
+func subscriptionLocation(id int) string {
+	return "/v1/subscriptions/" + strconv.Itoa(id)
+}
+
+// queryInt parses an optional integer query parameter, returning 0 when it is absent.
+func queryInt(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// unavailableRetryAfter is the Retry-After, in seconds, of responses to
+// requests the database had no connection for.
+const unavailableRetryAfter = "5"
+
+// writeUnavailable answers a request failed with ErrUnavailable. The repository
+// has already logged it.
+func (h *Handler) writeUnavailable(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", unavailableRetryAfter)
+	h.writeJSON(w, http.StatusServiceUnavailable, Response{Status: "error", Error: ErrUnavailable.Error()})
+}
+
 func (h *Handler) writeJSON(w http.ResponseWriter, status int, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
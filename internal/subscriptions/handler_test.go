@@ -4,31 +4,68 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-korel/user-subscriptions-api/internal/middleware"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockService struct {
-	GetAllSubscriptionsFunc   func(ctx context.Context) ([]Subscription, error)
-	GetSubscriptionByIDFunc   func(ctx context.Context, id int) (*Subscription, error)
-	CreateSubscriptionFunc    func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error)
-	UpdateSubscriptionFunc    func(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error)
-	DeleteSubscriptionFunc    func(ctx context.Context, id int) error
-	GetCostByPeriodFunc       func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error)
+	GetAllSubscriptionsFunc         func(ctx context.Context, sort Sort) ([]Subscription, error)
+	GetSubscriptionByIDFunc         func(ctx context.Context, id int) (*Subscription, error)
+	CreateSubscriptionFunc          func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error)
+	UpdateSubscriptionFunc          func(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error)
+	DeleteSubscriptionFunc          func(ctx context.Context, id int) (*Subscription, error)
+	GetCostByPeriodFunc             func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error)
+	GetCostVersionFunc              func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error)
+	GetServicesFunc                 func(ctx context.Context, filter ServiceFilter, limit, offset int) (*Page[string], error)
+	GetDateRangeFunc                func(ctx context.Context, userID *uuid.UUID) (*DateRangeResponse, error)
+	DeleteUserSubscriptionsFunc     func(ctx context.Context, userID uuid.UUID) (int64, error)
+	RenewSubscriptionFunc           func(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error)
+	ExportSubscriptionsFunc         func(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error)
+	GetSubscribersFunc              func(ctx context.Context, serviceName string, limit, offset int) (*Page[uuid.UUID], error)
+	GetRollingCostFunc              func(ctx context.Context, months int, userID *uuid.UUID) (*CostResponse, error)
+	ValidateSubscriptionFunc        func(ctx context.Context, req CreateSubscriptionRequest) []*ValidationError
+	GetStatsFunc                    func(ctx context.Context, fresh bool) (*StatsResponse, error)
+	GetUsersFunc                    func(ctx context.Context, limit, offset int) (*Page[UserSubscriptions], error)
+	GetSubscriptionByExternalIDFunc func(ctx context.Context, externalID string) (*Subscription, error)
+	GetCostWithIDsFunc              func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error)
+	ListSubscriptionsFunc           func(ctx context.Context, sort Sort) (*SubscriptionList, error)
+	GetChangesSinceFunc             func(ctx context.Context, since string) ([]Subscription, error)
+	GetMetaFunc                     func(ctx context.Context) *MetaResponse
+	RecomputeSummariesFunc          func(ctx context.Context) (*RecomputeSummariesResponse, error)
+	GetUserSummaryFunc              func(ctx context.Context, userID uuid.UUID) (*UserCostSummary, error)
+	GetServiceDiversityFunc         func(ctx context.Context, limit, offset int) (*Page[UserServices], error)
+	GetBudgetStatusFunc             func(ctx context.Context) ([]BudgetStatus, error)
+	CancelUserSubscriptionsFunc     func(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetCostByQueryFunc              func(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error)
+	PreviewCostChangeFunc           func(ctx context.Context, req CostPreviewRequest) (*CostPreviewResponse, error)
 }
 
-func (m *MockService) GetAllSubscriptions(ctx context.Context) ([]Subscription, error) {
+func (m *MockService) GetAllSubscriptions(ctx context.Context, sort Sort) ([]Subscription, error) {
 	if m.GetAllSubscriptionsFunc != nil {
-		return m.GetAllSubscriptionsFunc(ctx)
+		return m.GetAllSubscriptionsFunc(ctx, sort)
 	}
 	return []Subscription{}, nil
 }
 
+func (m *MockService) ListSubscriptions(ctx context.Context, sort Sort) (*SubscriptionList, error) {
+	if m.ListSubscriptionsFunc != nil {
+		return m.ListSubscriptionsFunc(ctx, sort)
+	}
+	return &SubscriptionList{Subscriptions: []Subscription{}}, nil
+}
+
 func (m *MockService) GetSubscriptionByID(ctx context.Context, id int) (*Subscription, error) {
 	if m.GetSubscriptionByIDFunc != nil {
 		return m.GetSubscriptionByIDFunc(ctx, id)
@@ -50,11 +87,11 @@ func (m *MockService) UpdateSubscription(ctx context.Context, id int, req Update
 	return nil, nil
 }
 
-func (m *MockService) DeleteSubscription(ctx context.Context, id int) error {
+func (m *MockService) DeleteSubscription(ctx context.Context, id int) (*Subscription, error) {
 	if m.DeleteSubscriptionFunc != nil {
 		return m.DeleteSubscriptionFunc(ctx, id)
 	}
-	return nil
+	return &Subscription{ID: id}, nil
 }
 
 func (m *MockService) GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
@@ -64,6 +101,160 @@ func (m *MockService) GetCostByPeriod(ctx context.Context, startDate, endDate st
 	return nil, nil
 }
 
+func (m *MockService) GetCostByQuery(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error) {
+	if m.GetCostByQueryFunc != nil {
+		return m.GetCostByQueryFunc(ctx, query)
+	}
+	return &CostWithIDsResponse{}, nil
+}
+
+func (m *MockService) PreviewCostChange(ctx context.Context, req CostPreviewRequest) (*CostPreviewResponse, error) {
+	if m.PreviewCostChangeFunc != nil {
+		return m.PreviewCostChangeFunc(ctx, req)
+	}
+	return &CostPreviewResponse{}, nil
+}
+
+func (m *MockService) GetCostWithIDs(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error) {
+	if m.GetCostWithIDsFunc != nil {
+		return m.GetCostWithIDsFunc(ctx, startDate, endDate, userID, serviceName)
+	}
+	return nil, nil
+}
+
+func (m *MockService) GetServices(ctx context.Context, filter ServiceFilter, limit, offset int) (*Page[string], error) {
+	if m.GetServicesFunc != nil {
+		return m.GetServicesFunc(ctx, filter, limit, offset)
+	}
+	return &Page[string]{Items: []string{}}, nil
+}
+
+func (m *MockService) GetDateRange(ctx context.Context, userID *uuid.UUID) (*DateRangeResponse, error) {
+	if m.GetDateRangeFunc != nil {
+		return m.GetDateRangeFunc(ctx, userID)
+	}
+	return &DateRangeResponse{}, nil
+}
+
+func (m *MockService) CancelUserSubscriptions(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if m.CancelUserSubscriptionsFunc != nil {
+		return m.CancelUserSubscriptionsFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+func (m *MockService) DeleteUserSubscriptions(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if m.DeleteUserSubscriptionsFunc != nil {
+		return m.DeleteUserSubscriptionsFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+func (m *MockService) RenewSubscription(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error) {
+	if m.RenewSubscriptionFunc != nil {
+		return m.RenewSubscriptionFunc(ctx, id, req)
+	}
+	return nil, nil
+}
+
+func (m *MockService) GetCostVersion(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error) {
+	if m.GetCostVersionFunc != nil {
+		return m.GetCostVersionFunc(ctx, startDate, endDate, userID, serviceName)
+	}
+	return &CostVersion{StartDate: startDate, EndDate: endDate}, nil
+}
+
+func (m *MockService) ExportSubscriptions(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error) {
+	if m.ExportSubscriptionsFunc != nil {
+		return m.ExportSubscriptionsFunc(ctx, filter)
+	}
+	return []Subscription{}, nil
+}
+
+func (m *MockService) GetSubscribers(ctx context.Context, serviceName string, limit, offset int) (*Page[uuid.UUID], error) {
+	if m.GetSubscribersFunc != nil {
+		return m.GetSubscribersFunc(ctx, serviceName, limit, offset)
+	}
+	return &Page[uuid.UUID]{Items: []uuid.UUID{}}, nil
+}
+
+func (m *MockService) GetRollingCost(ctx context.Context, months int, userID *uuid.UUID) (*CostResponse, error) {
+	if m.GetRollingCostFunc != nil {
+		return m.GetRollingCostFunc(ctx, months, userID)
+	}
+	return &CostResponse{}, nil
+}
+
+func (m *MockService) GetStats(ctx context.Context, fresh bool) (*StatsResponse, error) {
+	if m.GetStatsFunc != nil {
+		return m.GetStatsFunc(ctx, fresh)
+	}
+	return &StatsResponse{}, nil
+}
+
+func (m *MockService) GetUsers(ctx context.Context, limit, offset int) (*Page[UserSubscriptions], error) {
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc(ctx, limit, offset)
+	}
+	return &Page[UserSubscriptions]{Items: []UserSubscriptions{}}, nil
+}
+
+func (m *MockService) GetBudgetStatus(ctx context.Context) ([]BudgetStatus, error) {
+	if m.GetBudgetStatusFunc != nil {
+		return m.GetBudgetStatusFunc(ctx)
+	}
+	return []BudgetStatus{}, nil
+}
+
+func (m *MockService) GetServiceDiversity(ctx context.Context, limit, offset int) (*Page[UserServices], error) {
+	if m.GetServiceDiversityFunc != nil {
+		return m.GetServiceDiversityFunc(ctx, limit, offset)
+	}
+	return &Page[UserServices]{Items: []UserServices{}}, nil
+}
+
+func (m *MockService) GetSubscriptionByExternalID(ctx context.Context, externalID string) (*Subscription, error) {
+	if m.GetSubscriptionByExternalIDFunc != nil {
+		return m.GetSubscriptionByExternalIDFunc(ctx, externalID)
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockService) GetChangesSince(ctx context.Context, since string) ([]Subscription, error) {
+	if m.GetChangesSinceFunc != nil {
+		return m.GetChangesSinceFunc(ctx, since)
+	}
+	return []Subscription{}, nil
+}
+
+func (m *MockService) GetMeta(ctx context.Context) *MetaResponse {
+	if m.GetMetaFunc != nil {
+		return m.GetMetaFunc(ctx)
+	}
+	return &MetaResponse{}
+}
+
+func (m *MockService) RecomputeSummaries(ctx context.Context) (*RecomputeSummariesResponse, error) {
+	if m.RecomputeSummariesFunc != nil {
+		return m.RecomputeSummariesFunc(ctx)
+	}
+	return &RecomputeSummariesResponse{}, nil
+}
+
+func (m *MockService) GetUserSummary(ctx context.Context, userID uuid.UUID) (*UserCostSummary, error) {
+	if m.GetUserSummaryFunc != nil {
+		return m.GetUserSummaryFunc(ctx, userID)
+	}
+	return nil, ErrSummaryNotFound
+}
+
+func (m *MockService) ValidateSubscription(ctx context.Context, req CreateSubscriptionRequest) []*ValidationError {
+	if m.ValidateSubscriptionFunc != nil {
+		return m.ValidateSubscriptionFunc(ctx, req)
+	}
+	return nil
+}
+
 func TestGetSubscriptions_Success(t *testing.T) {
 	mockService := &MockService{}
 	mockLog := &MockLogger{}
@@ -79,8 +270,8 @@ func TestGetSubscriptions_Success(t *testing.T) {
 		},
 	}
 
-	mockService.GetAllSubscriptionsFunc = func(ctx context.Context) ([]Subscription, error) {
-		return testSubs, nil
+	mockService.ListSubscriptionsFunc = func(ctx context.Context, sort Sort) (*SubscriptionList, error) {
+		return &SubscriptionList{Subscriptions: testSubs}, nil
 	}
 
 	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
@@ -136,6 +327,108 @@ func TestHandlerCreateSubscription_Success(t *testing.T) {
 	assert.Equal(t, "success", response.Status)
 }
 
+func TestHandlerCreateSubscription_Location(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.CreateSubscriptionFunc = func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+		return &Subscription{ID: 42, ServiceName: req.ServiceName, Price: req.Price, UserID: req.UserID, StartDate: req.StartDate}, nil
+	}
+
+	body := `{"service_name":"Netflix","price":100,"user_id":"` + uuid.New().String() + `","start_date":"01-2025"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handler.CreateSubscription(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/v1/subscriptions/42", w.Header().Get("Location"))
+}
+
+func TestHandlerGetSubscription(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.GetSubscriptionByIDFunc = func(ctx context.Context, id int) (*Subscription, error) {
+		if id != 42 {
+			return nil, ErrNotFound
+		}
+		return &Subscription{ID: 42, ServiceName: "Netflix", Price: 100, StartDate: "01-2025"}, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{name: "Found", path: "/v1/subscriptions/42", expected: http.StatusOK},
+		{name: "Not found", path: "/v1/subscriptions/7", expected: http.StatusNotFound},
+		{name: "Invalid ID", path: "/v1/subscriptions/abc", expected: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestHandlerGetSubscription_NotFoundBody(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.GetSubscriptionByIDFunc = func(ctx context.Context, id int) (*Subscription, error) {
+		return nil, fmt.Errorf("failed to get subscription: %w: %w", ErrNotFound, pgx.ErrNoRows)
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/7", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"status":"error","data":null,"code":"not_found","error":"subscription not found"}`, w.Body.String())
+	assert.NotContains(t, w.Body.String(), pgx.ErrNoRows.Error())
+	assert.NotContains(t, w.Body.String(), "failed to get subscription")
+}
+
+func TestHandlerGetSubscription_TooManyConnections(t *testing.T) {
+	db := &flakyDB{err: &pgconn.PgError{Code: tooManyConnections, Message: "sorry, too many clients already"}, failures: 1}
+	repoLog := &recordingLogger{}
+	handler := NewHandler(NewService(NewRepository(db, repoLog), &MockLogger{}), &MockLogger{})
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/7", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, unavailableRetryAfter, w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"status":"error","data":null,"error":"database is unavailable"}`, w.Body.String())
+
+	if assert.NotEmpty(t, repoLog.entries) {
+		assert.Equal(t, "warn", repoLog.entries[0].level)
+		assert.Equal(t, "Database connection limit reached", repoLog.entries[0].message)
+	}
+}
+
 func TestCreateSubscription_InvalidJSON(t *testing.T) {
 	mockService := &MockService{}
 	mockLog := &MockLogger{}
@@ -229,8 +522,8 @@ func TestHandlerDeleteSubscription_Success(t *testing.T) {
 	mockLog := &MockLogger{}
 	handler := NewHandler(mockService, mockLog)
 
-	mockService.DeleteSubscriptionFunc = func(ctx context.Context, id int) error {
-		return nil
+	mockService.DeleteSubscriptionFunc = func(ctx context.Context, id int) (*Subscription, error) {
+		return &Subscription{ID: id, ServiceName: "Netflix", Price: 100}, nil
 	}
 
 	req := httptest.NewRequest(http.MethodDelete, "/v1/subscriptions/1", nil)
@@ -242,14 +535,59 @@ func TestHandlerDeleteSubscription_Success(t *testing.T) {
 
 	handler.DeleteSubscription(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
 
-	var response Response
-	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
-    	t.Fatalf("failed to decode response: %v", err)
+func TestHandlerDeleteSubscription_NotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		strict   bool
+		expected int
+	}{
+		{name: "Idempotent mode", strict: false, expected: http.StatusNoContent},
+		{name: "Strict mode", strict: true, expected: http.StatusNotFound},
 	}
 
-	assert.Equal(t, "success", response.Status)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockLog := &MockLogger{}
+			handler := NewHandler(mockService, mockLog, WithStrictDelete(tt.strict))
+
+			mockService.DeleteSubscriptionFunc = func(ctx context.Context, id int) (*Subscription, error) {
+				return nil, ErrNotFound
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/v1/subscriptions/1", nil)
+			w := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			handler.DeleteSubscription(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestHandlerDeleteSubscription_StrictModeSuccess(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog, WithStrictDelete(true))
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/subscriptions/1", nil)
+	w := httptest.NewRecorder()
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	handler.DeleteSubscription(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
 }
 
 func TestHandlerGetCostByPeriod_Success(t *testing.T) {
@@ -279,6 +617,24 @@ func TestHandlerGetCostByPeriod_Success(t *testing.T) {
 	assert.Equal(t, "success", response.Status)
 }
 
+func TestHandlerGetCostByPeriod_BeyondInt32(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+		return &CostResponse{TotalCost: 6442450941, Count: 3}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost?start_date=01-2025", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetCostByPeriod(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"total_cost":6442450941,"count":3}}`, w.Body.String())
+}
+
 func TestGetCostByPeriod_InvalidUserID(t *testing.T) {
 	mockService := &MockService{}
 	mockLog := &MockLogger{}
@@ -298,4 +654,1786 @@ func TestGetCostByPeriod_InvalidUserID(t *testing.T) {
 
 	assert.Equal(t, "error", response.Status)
 	assert.Contains(t, response.Error, "Invalid user ID format")
-}
\ No newline at end of file
+}
+
+func TestHandlerGetCostByPeriod_Year(t *testing.T) {
+	cost := func(t *testing.T, query string) (int, string) {
+		t.Helper()
+		mockService := &MockService{}
+		handler := NewHandler(mockService, &MockLogger{})
+		mockService.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+			if startDate == "01-2025" && endDate == "12-2025" {
+				return &CostResponse{TotalCost: 1200, Count: 12}, nil
+			}
+			return &CostResponse{}, nil
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost?"+query, nil)
+		w := httptest.NewRecorder()
+		handler.GetCostByPeriod(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	yearCode, yearBody := cost(t, "year=2025")
+	rangeCode, rangeBody := cost(t, "start_date=01-2025&end_date=12-2025")
+	assert.Equal(t, http.StatusOK, yearCode)
+	assert.Equal(t, rangeCode, yearCode)
+	assert.Equal(t, rangeBody, yearBody)
+	assert.Contains(t, yearBody, `"total_cost":1200`)
+
+	tests := []struct {
+		name   string
+		query  string
+		errMsg string
+	}{
+		{name: "Combined with start date", query: "year=2025&start_date=01-2025", errMsg: "cannot be combined"},
+		{name: "Combined with end date", query: "year=2025&end_date=12-2025", errMsg: "cannot be combined"},
+		{name: "Two digits", query: "year=25", errMsg: "four-digit year"},
+		{name: "Not a number", query: "year=abcd", errMsg: "four-digit year"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := cost(t, tt.query)
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Contains(t, body, tt.errMsg)
+		})
+	}
+}
+
+func TestHandlerGetServices_Success(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.GetServicesFunc = func(ctx context.Context, filter ServiceFilter, limit, offset int) (*Page[string], error) {
+		assert.Equal(t, ServiceFilter{Prefix: "net"}, filter)
+		assert.Equal(t, 10, limit)
+		assert.Equal(t, 20, offset)
+		return &Page[string]{Items: []string{"Netflix"}, Total: 35, Limit: limit, Offset: offset, NextCursor: "21"}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/services?prefix=net&limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetServices(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"data": {"items": ["Netflix"], "total": 35, "limit": 10, "offset": 20, "next_cursor": "21"}
+	}`, w.Body.String())
+}
+
+func TestHandlerGetServices_Query(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	var got ServiceFilter
+	mockService.GetServicesFunc = func(ctx context.Context, filter ServiceFilter, limit, offset int) (*Page[string], error) {
+		got = filter
+		return &Page[string]{Items: []string{"net", "Netflix", "Planet"}, Total: 3, Limit: limit, Offset: offset}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/services?q=net", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetServices(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ServiceFilter{Query: "net"}, got)
+}
+
+func TestGetServices_InvalidLimit(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/services?limit=abc", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetServices(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response Response
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	assert.Equal(t, "error", response.Status)
+	assert.Contains(t, response.Error, "Invalid limit")
+}
+
+func TestHandlerGetDateRange_Success(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	userID := uuid.New()
+	minStart := "01-2024"
+
+	mockService.GetDateRangeFunc = func(ctx context.Context, uid *uuid.UUID) (*DateRangeResponse, error) {
+		assert.Equal(t, userID, *uid)
+		return &DateRangeResponse{MinStartDate: &minStart}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/date-range?user_id="+userID.String(), nil)
+	w := httptest.NewRecorder()
+
+	handler.GetDateRange(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"min_start_date":"01-2024","max_end_date":null}}`, w.Body.String())
+}
+
+func TestHandlerDeleteUserSubscriptions_Success(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	userID := uuid.New()
+	mockService.DeleteUserSubscriptionsFunc = func(ctx context.Context, uid uuid.UUID) (int64, error) {
+		assert.Equal(t, userID, uid)
+		return 3, nil
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/users/"+userID.String()+"/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user_id", userID.String())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	handler.DeleteUserSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"deleted":3}}`, w.Body.String())
+}
+
+func TestHandlerDeleteUserSubscriptions_InvalidUserID(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/users/invalid/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user_id", "invalid")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	handler.DeleteUserSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response Response
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	assert.Equal(t, "error", response.Status)
+	assert.Contains(t, response.Error, "Invalid user ID format")
+}
+
+// allowAdmin lets every request through to the admin endpoints.
+func allowAdmin(next http.Handler) http.Handler {
+	return next
+}
+
+func TestHandlerDeleteUserSubscriptions_RequiresAdminKey(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog,
+		WithAdminAuth(middleware.APIKey(middleware.APIKeyHeader, "admin-secret", nil, mockLog)))
+
+	called := false
+	mockService.DeleteUserSubscriptionsFunc = func(ctx context.Context, uid uuid.UUID) (int64, error) {
+		called = true
+		return 1, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	target := "/v1/users/" + uuid.New().String() + "/subscriptions"
+
+	for _, key := range []string{"", "wrong-secret"} {
+		req := httptest.NewRequest(http.MethodDelete, target, nil)
+		if key != "" {
+			req.Header.Set(middleware.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"status":"error","data":null,"error":"Unauthorized"}`, w.Body.String())
+		assert.False(t, called)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, target, nil)
+	req.Header.Set(middleware.APIKeyHeader, "admin-secret")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"deleted":1}}`, w.Body.String())
+	assert.True(t, called)
+}
+
+func TestHandlerRecomputeSummaries_ExemptedRoute(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	exempt := middleware.Exemptions{"/v1/admin/*"}
+	handler := NewHandler(mockService, mockLog,
+		WithAdminAuth(middleware.APIKey(middleware.APIKeyHeader, "admin-secret", exempt, mockLog)))
+
+	mockService.RecomputeSummariesFunc = func(ctx context.Context) (*RecomputeSummariesResponse, error) {
+		return &RecomputeSummariesResponse{Users: 1}, nil
+	}
+	mockService.DeleteUserSubscriptionsFunc = func(ctx context.Context, uid uuid.UUID) (int64, error) {
+		t.Fatal("bulk delete is not exempted")
+		return 0, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/recompute-summaries", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/v1/users/"+uuid.New().String()+"/subscriptions", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestHandlerDeleteUserSubscriptions_NoAdminAuth(t *testing.T) {
+	mockService := &MockService{}
+	handler := NewHandler(mockService, &MockLogger{})
+
+	mockService.DeleteUserSubscriptionsFunc = func(ctx context.Context, uid uuid.UUID) (int64, error) {
+		t.Fatal("bulk delete must not be served without admin auth")
+		return 0, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/users/"+uuid.New().String()+"/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlerCancelUserSubscriptions(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog,
+		WithAdminAuth(middleware.APIKey(middleware.APIKeyHeader, "admin-secret", nil, mockLog)))
+
+	userID := uuid.New()
+	var gotUserID uuid.UUID
+	mockService.CancelUserSubscriptionsFunc = func(ctx context.Context, uid uuid.UUID) (int64, error) {
+		gotUserID = uid
+		return 2, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/users/"+userID.String()+"/subscriptions/cancel", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"status":"error","data":null,"error":"Unauthorized"}`, w.Body.String())
+	assert.Equal(t, uuid.Nil, gotUserID)
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/users/"+userID.String()+"/subscriptions/cancel", nil)
+	req.Header.Set(middleware.APIKeyHeader, "admin-secret")
+	w = httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, gotUserID)
+	assert.JSONEq(t, `{"status":"success","data":{"cancelled":2}}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/users/invalid/subscriptions/cancel", nil)
+	req.Header.Set(middleware.APIKeyHeader, "admin-secret")
+	w = httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"status":"error","data":null,"error":"Invalid user ID format"}`, w.Body.String())
+}
+
+func TestHandlerUserEndpoints_MalformedUserID(t *testing.T) {
+	mockService := &MockService{}
+	handler := NewHandler(mockService, &MockLogger{}, WithAdminAuth(allowAdmin))
+
+	called := false
+	mockService.DeleteUserSubscriptionsFunc = func(ctx context.Context, userID uuid.UUID) (int64, error) {
+		called = true
+		return 0, nil
+	}
+	mockService.CancelUserSubscriptionsFunc = func(ctx context.Context, userID uuid.UUID) (int64, error) {
+		called = true
+		return 0, nil
+	}
+	mockService.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+		called = true
+		return &CostResponse{}, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	endpoints := []struct {
+		method string
+		target func(userID string) string
+	}{
+		{method: http.MethodDelete, target: func(userID string) string { return "/v1/users/" + userID + "/subscriptions" }},
+		{method: http.MethodPost, target: func(userID string) string { return "/v1/users/" + userID + "/subscriptions/cancel" }},
+		{method: http.MethodGet, target: func(userID string) string { return "/v1/subscriptions/cost?start_date=01-2025&user_id=" + userID }},
+	}
+
+	for _, userID := range []string{"invalid", "550e8400", "550e8400-e29b-41d4-a716-44665544000g", "550e8400-e29b-41d4-a716-4466554400000"} {
+		for _, endpoint := range endpoints {
+			target := endpoint.target(userID)
+			t.Run(endpoint.method+" "+target, func(t *testing.T) {
+				req := httptest.NewRequest(endpoint.method, target, nil)
+				w := httptest.NewRecorder()
+
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.JSONEq(t, `{"status":"error","data":null,"error":"Invalid user ID format"}`, w.Body.String())
+				assert.False(t, called)
+			})
+		}
+	}
+}
+
+func TestHandlerRenewSubscription_Success(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.RenewSubscriptionFunc = func(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error) {
+		return &Subscription{
+			ID:            2,
+			ServiceName:   "Netflix",
+			Price:         100,
+			StartDate:     req.StartDate,
+			EndDate:       req.EndDate,
+			RenewedFromID: &id,
+		}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions/1/renew", bytes.NewBufferString(`{"start_date":"01-2026","end_date":"12-2026"}`))
+	w := httptest.NewRecorder()
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	handler.RenewSubscription(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Status string       `json:"status"`
+		Data   Subscription `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, 2, response.Data.ID)
+	if assert.NotNil(t, response.Data.RenewedFromID) {
+		assert.Equal(t, 1, *response.Data.RenewedFromID)
+	}
+}
+
+func TestHandlerRenewSubscription_NotFound(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.RenewSubscriptionFunc = func(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error) {
+		return nil, ErrNotFound
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions/99/renew", bytes.NewBufferString(`{"start_date":"01-2026"}`))
+	w := httptest.NewRecorder()
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "99")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	handler.RenewSubscription(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlerUpdateSubscription_UserIDChangeRejected(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.UpdateSubscriptionFunc = func(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error) {
+		return nil, ErrUserIDImmutable
+	}
+
+	body, _ := json.Marshal(UpdateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       100,
+		UserID:      uuid.New(),
+		StartDate:   "01-2025",
+	})
+	req := httptest.NewRequest(http.MethodPatch, "/v1/subscriptions/1", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	handler.UpdateSubscription(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response Response
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	assert.Equal(t, "error", response.Status)
+	assert.Equal(t, "user_id cannot be changed", response.Error)
+}
+
+func TestHandlerGetCostByPeriod_NotModified(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	lastModified := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	version := &CostVersion{StartDate: "01-2025", Count: 2, LastModified: &lastModified}
+	mockService.GetCostVersionFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error) {
+		return version, nil
+	}
+	mockService.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+		t.Fatal("cost must not be recomputed when nothing changed")
+		return nil, nil
+	}
+
+	for _, ifNoneMatch := range []string{version.ETag(), `"other", W/` + version.ETag(), "*"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost?start_date=01-2025", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+
+		handler.GetCostByPeriod(w, req)
+
+		assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, version.ETag(), w.Header().Get("ETag"))
+	}
+}
+
+func TestHandlerGetCostByPeriod_IfModifiedSince(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	lastModified := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	mockService.GetCostVersionFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error) {
+		return &CostVersion{StartDate: "01-2025", Count: 2, LastModified: &lastModified}, nil
+	}
+	mockService.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+		return &CostResponse{TotalCost: 150, Count: 2}, nil
+	}
+
+	tests := []struct {
+		name     string
+		since    time.Time
+		expected int
+	}{
+		{name: "Unchanged since", since: lastModified, expected: http.StatusNotModified},
+		{name: "Changed since", since: lastModified.Add(-time.Hour), expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost?start_date=01-2025", nil)
+			req.Header.Set("If-Modified-Since", tt.since.Format(http.TimeFormat))
+			w := httptest.NewRecorder()
+
+			handler.GetCostByPeriod(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			assert.Equal(t, lastModified.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+			assert.NotEmpty(t, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHandlerGetCostByPeriod_Modified(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	lastModified := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	before := CostVersion{StartDate: "01-2025", Count: 3, LastModified: &lastModified}
+	after := CostVersion{StartDate: "01-2025", Count: 2, LastModified: &lastModified}
+	mockService.GetCostVersionFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error) {
+		return &after, nil
+	}
+	mockService.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+		return &CostResponse{TotalCost: 150, Count: 2}, nil
+	}
+
+	// A delete lowers the count without moving MAX(updated_at).
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost?start_date=01-2025", nil)
+	req.Header.Set("If-None-Match", before.ETag())
+	req.Header.Set("If-Modified-Since", lastModified.Format(http.TimeFormat))
+	w := httptest.NewRecorder()
+
+	handler.GetCostByPeriod(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, after.ETag(), w.Header().Get("ETag"))
+	assert.NotEqual(t, before.ETag(), after.ETag())
+	assert.NotEmpty(t, w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"status":"success","data":{"total_cost":150,"count":2}}`, w.Body.String())
+}
+
+func TestHandler_EmptyBody(t *testing.T) {
+	bodies := map[string]string{
+		"Zero-length": "",
+		"Whitespace":  " \n\t ",
+	}
+
+	for name, body := range bodies {
+		t.Run("Create/"+name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockLog := &MockLogger{}
+			handler := NewHandler(mockService, mockLog)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+
+			handler.CreateSubscription(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"status":"error","data":null,"error":"request body is empty"}`, w.Body.String())
+		})
+
+		t.Run("Update/"+name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockLog := &MockLogger{}
+			handler := NewHandler(mockService, mockLog)
+
+			req := httptest.NewRequest(http.MethodPatch, "/v1/subscriptions/1", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			handler.UpdateSubscription(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"status":"error","data":null,"error":"request body is empty"}`, w.Body.String())
+		})
+	}
+}
+
+func TestHandlerExportSubscriptions_Filters(t *testing.T) {
+	userID := uuid.New()
+	serviceName := "Netflix"
+
+	tests := []struct {
+		name   string
+		query  string
+		filter SubscriptionFilter
+	}{
+		{name: "No filters", query: "", filter: SubscriptionFilter{}},
+		{name: "Start date", query: "start_date=01-2025", filter: SubscriptionFilter{StartDate: "01-2025"}},
+		{name: "End date", query: "end_date=03-2025", filter: SubscriptionFilter{EndDate: "03-2025"}},
+		{name: "User ID", query: "user_id=" + userID.String(), filter: SubscriptionFilter{UserID: &userID}},
+		{name: "Service name", query: "service_name=Netflix", filter: SubscriptionFilter{ServiceName: &serviceName}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockLog := &MockLogger{}
+			handler := NewHandler(mockService, mockLog)
+
+			var got SubscriptionFilter
+			mockService.ExportSubscriptionsFunc = func(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error) {
+				got = filter
+				return []Subscription{}, nil
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/export?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ExportSubscriptions(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.filter, got)
+		})
+	}
+}
+
+func TestHandlerExportSubscriptions_Formats(t *testing.T) {
+	endDate := "12-2025"
+	externalID := "ext-42"
+	sub := Subscription{
+		ID:            1,
+		ServiceName:   "Netflix",
+		Price:         100,
+		UserID:        uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+		StartDate:     "01-2025",
+		EndDate:       &endDate,
+		ExternalID:    &externalID,
+		ReferenceCode: "SUB-0001",
+		CreatedAt:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		UpdatedAt:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+	mockService.ExportSubscriptionsFunc = func(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error) {
+		return []Subscription{sub}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/export", nil)
+	w := httptest.NewRecorder()
+	handler.ExportSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id,service_name,price,user_id,start_date,end_date,renewed_from_id,external_id,reference_code,created_at,updated_at\n"+
+		"1,Netflix,100,550e8400-e29b-41d4-a716-446655440000,01-2025,12-2025,,ext-42,SUB-0001,2025-01-15T10:00:00Z,2025-01-15T10:00:00Z\n", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/subscriptions/export?format=jsonl", nil)
+	w = httptest.NewRecorder()
+	handler.ExportSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"service_name":"Netflix"`)
+	assert.Contains(t, w.Body.String(), `"external_id":"ext-42"`)
+	assert.Contains(t, w.Body.String(), `"reference_code":"SUB-0001"`)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/subscriptions/export?format=table", nil)
+	w = httptest.NewRecorder()
+	handler.ExportSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, flatRecordHeader, strings.Fields(lines[0]))
+		assert.Equal(t, sub.toFlatRecord()[:6], strings.Fields(lines[1])[:6])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/subscriptions/export?format=xml", nil)
+	w = httptest.NewRecorder()
+	handler.ExportSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandlerRegisterRoutes_DisabledEndpoint(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog, WithDisabledEndpoints("export", "bulk-delete"))
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected int
+	}{
+		{name: "Disabled export", method: http.MethodGet, path: "/v1/subscriptions/export", expected: http.StatusNotFound},
+		{name: "Disabled bulk delete", method: http.MethodDelete, path: "/v1/users/" + uuid.New().String() + "/subscriptions", expected: http.StatusNotFound},
+		{name: "Enabled list", method: http.MethodGet, path: "/v1/subscriptions", expected: http.StatusOK},
+		{name: "Enabled services", method: http.MethodGet, path: "/v1/subscriptions/services", expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestHandlerCheckEndpointNames(t *testing.T) {
+	noop := func(next http.Handler) http.Handler { return next }
+
+	tests := []struct {
+		name     string
+		opts     []HandlerOption
+		expected string
+	}{
+		{
+			name: "Known names",
+			opts: []HandlerOption{
+				WithDisabledEndpoints("export", "bulk-delete"),
+				WithEndpointMiddleware("cost", noop),
+				WithEndpointTimeouts(time.Second, map[string]time.Duration{"export": time.Minute}),
+			},
+		},
+		{
+			name: "Unknown names",
+			opts: []HandlerOption{
+				WithDisabledEndpoints("exprot", "bulk-delete"),
+				WithEndpointMiddleware("cots", noop),
+				WithEndpointTimeouts(time.Second, map[string]time.Duration{"exprot": time.Minute, "stats": time.Second}),
+			},
+			expected: "unknown endpoints: cots, exprot",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(&MockService{}, &MockLogger{}, tt.opts...)
+			handler.RegisterRoutes(chi.NewRouter())
+
+			err := handler.CheckEndpointNames()
+
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandlerRegisterRoutes_EndpointMiddleware(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	teapot := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+	}
+	handler := NewHandler(mockService, mockLog, WithEndpointMiddleware("cost", teapot))
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost?start_date=01-2025", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTeapot, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHandlerRegisterRoutes_EndpointTimeouts(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	slow := func(ctx context.Context) error {
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	mockService.ExportSubscriptionsFunc = func(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error) {
+		if err := slow(ctx); err != nil {
+			return nil, err
+		}
+		return []Subscription{}, nil
+	}
+	mockService.CreateSubscriptionFunc = func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+		if err := slow(ctx); err != nil {
+			return nil, err
+		}
+		return &Subscription{ID: 1}, nil
+	}
+	handler := NewHandler(mockService, mockLog, WithEndpointTimeouts(20*time.Millisecond, map[string]time.Duration{"export": time.Second}))
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/export", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := `{"service_name":"Netflix","price":400,"user_id":"` + uuid.New().String() + `","start_date":"01-2025"}`
+	req = httptest.NewRequest(http.MethodPost, "/v1/subscriptions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, timeoutBody, w.Body.String())
+}
+
+func TestGetSubscriptions_Sort(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	var got Sort
+	mockService.ListSubscriptionsFunc = func(ctx context.Context, sort Sort) (*SubscriptionList, error) {
+		got = sort
+		return &SubscriptionList{Subscriptions: []Subscription{}}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions?sort_by=price&order=desc", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, Sort{Field: "price", Order: "desc"}, got)
+}
+
+func TestGetSubscriptions_MaliciousSortRejected(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(NewService(mockRepo, mockLog), mockLog)
+
+	reachedRepository := false
+	mockRepo.GetAllFunc = func(ctx context.Context, sort Sort, limit int) ([]Subscription, error) {
+		reachedRepository = true
+		return []Subscription{}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions?sort_by="+url.QueryEscape("price; DROP TABLE subscriptions"), nil)
+	w := httptest.NewRecorder()
+
+	handler.GetSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, reachedRepository, "invalid sort must not reach the repository")
+}
+
+func TestHandlerGetSubscribers_Success(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	userID := uuid.New()
+	var gotServiceName string
+	var gotLimit, gotOffset int
+	mockService.GetSubscribersFunc = func(ctx context.Context, serviceName string, limit, offset int) (*Page[uuid.UUID], error) {
+		gotServiceName, gotLimit, gotOffset = serviceName, limit, offset
+		return &Page[uuid.UUID]{Items: []uuid.UUID{userID}, Total: 21, Limit: limit, Offset: offset}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/subscribers?service_name=Netflix&limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetSubscribers(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Netflix", gotServiceName)
+	assert.Equal(t, 10, gotLimit)
+	assert.Equal(t, 20, gotOffset)
+
+	assert.JSONEq(t, `{
+		"status": "success",
+		"data": {"items": ["`+userID.String()+`"], "total": 21, "limit": 10, "offset": 20}
+	}`, w.Body.String())
+}
+
+func TestHandlerGetSubscribers_Empty(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/subscribers?service_name=Unknown", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetSubscribers(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"items":[],"total":0,"limit":0,"offset":0}}`, w.Body.String())
+}
+
+func TestHandlerResponseShape(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	createdAt := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.CreateSubscriptionFunc = func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+		return &Subscription{
+			ID:            1,
+			ServiceName:   req.ServiceName,
+			Price:         req.Price,
+			UserID:        req.UserID,
+			StartDate:     req.StartDate,
+			ReferenceCode: "SUB-0123456789AB",
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt,
+		}, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	t.Run("Create", func(t *testing.T) {
+		body := `{"service_name":"Netflix","price":100,"user_id":"` + userID.String() + `","start_date":"01-2025"}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{
+			"status": "success",
+			"data": {
+				"id": 1,
+				"service_name": "Netflix",
+				"price": 100,
+				"user_id": "550e8400-e29b-41d4-a716-446655440000",
+				"start_date": "01-2025",
+				"end_date": null,
+				"renewed_from_id": null,
+				"external_id": null,
+				"reference_code": "SUB-0123456789AB",
+				"created_at": "2025-01-15T10:00:00Z",
+				"updated_at": "2025-01-15T10:00:00Z",
+				"active": false,
+				"next_billing_date": null
+			}
+		}`, w.Body.String())
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/v1/subscriptions/1", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/v1/subscriptions/abc", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"status":"error","data":null,"error":"Invalid subscription ID"}`, w.Body.String())
+	})
+}
+
+
+func TestHandlerDescribeSubscriptions(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	allow := strings.Split(w.Header().Get("Allow"), ", ")
+	assert.Contains(t, allow, http.MethodGet)
+	assert.Contains(t, allow, http.MethodPost)
+
+	var response struct {
+		Status string                 `json:"status"`
+		Data   []OperationDescription `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	assert.Equal(t, "success", response.Status)
+	assert.Len(t, response.Data, 3)
+	assert.Equal(t, http.MethodGet, response.Data[0].Method)
+	assert.Contains(t, response.Data[0].Parameters, "sort_by")
+}
+
+func TestHandlerDescribeSubscriptions_DisabledOperation(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog, WithDisabledEndpoints("create"))
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	handler.DescribeSubscriptions(w, req)
+
+	assert.Equal(t, "GET, OPTIONS", w.Header().Get("Allow"))
+}
+
+func TestHandlerGetRollingCost(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	userID := uuid.New()
+	var gotMonths int
+	var gotUserID *uuid.UUID
+	mockService.GetRollingCostFunc = func(ctx context.Context, months int, userID *uuid.UUID) (*CostResponse, error) {
+		gotMonths, gotUserID = months, userID
+		return &CostResponse{TotalCost: 300, Count: 2}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost/rolling?months=12&user_id="+userID.String(), nil)
+	w := httptest.NewRecorder()
+
+	handler.GetRollingCost(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 12, gotMonths)
+	assert.Equal(t, &userID, gotUserID)
+	assert.JSONEq(t, `{"status":"success","data":{"total_cost":300,"count":2}}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost/rolling?months=twelve", nil)
+	w = httptest.NewRecorder()
+
+	handler.GetRollingCost(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+func TestHandlerValidateSubscription(t *testing.T) {
+	mockRepo := &MockRepository{
+		CreateFunc: func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+			t.Fatal("validation must not create a subscription")
+			return nil, nil
+		},
+	}
+	handler := NewHandler(NewService(mockRepo, &MockLogger{}), &MockLogger{})
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   ValidationResponse
+	}{
+		{
+			name:   "Valid payload",
+			body:   `{"service_name":"Netflix","price":"100","user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2025"}`,
+			status: http.StatusOK,
+			want:   ValidationResponse{Valid: true},
+		},
+		{
+			name:   "Multiple violations",
+			body:   `{"service_name":"","price":0,"start_date":"2025-01"}`,
+			status: http.StatusOK,
+			want: ValidationResponse{Errors: []*ValidationError{
+				{Field: "service_name", Message: "service_name is required"},
+				{Field: "price", Message: "price must be greater than 0"},
+				{Field: "user_id", Message: "user_id is required and must be valid UUID"},
+				{Field: "start_date", Message: "date must be in MM-YYYY format"},
+			}},
+		},
+		{
+			name:   "Invalid JSON",
+			body:   `{"service_name":`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			handler.RegisterRoutes(r)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions/validate", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Status string             `json:"status"`
+				Data   ValidationResponse `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			assert.Equal(t, "success", response.Status)
+			assert.Equal(t, tt.want, response.Data)
+		})
+	}
+}
+
+func TestHandlerGetStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		fresh  bool
+	}{
+		{name: "Cached", query: "", status: http.StatusOK, fresh: false},
+		{name: "Fresh", query: "?fresh=true", status: http.StatusOK, fresh: true},
+		{name: "Invalid fresh", query: "?fresh=maybe", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			handler := NewHandler(mockService, &MockLogger{})
+
+			var gotFresh bool
+			mockService.GetStatsFunc = func(ctx context.Context, fresh bool) (*StatsResponse, error) {
+				gotFresh = fresh
+				return &StatsResponse{Subscriptions: 3, ComputedAt: time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)}, nil
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/stats"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.GetStats(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.fresh, gotFresh)
+				assert.Contains(t, w.Body.String(), `"computed_at":"2025-03-10T12:00:00Z"`)
+			}
+		})
+	}
+}
+
+func TestHandlerGetUserSummary(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+
+	tests := []struct {
+		name     string
+		query    string
+		err      error
+		status   int
+		expected string
+	}{
+		{
+			name:     "Success",
+			query:    "?user_id=" + userID.String(),
+			status:   http.StatusOK,
+			expected: `{"status":"success","data":{"user_id":"550e8400-e29b-41d4-a716-446655440000","monthly_cost":600,"active_subscriptions":2,"computed_at":"2025-03-10T12:00:00Z"}}`,
+		},
+		{name: "Not summarized", query: "?user_id=" + userID.String(), err: ErrSummaryNotFound, status: http.StatusNotFound},
+		{name: "Missing user ID", query: "", status: http.StatusBadRequest},
+		{name: "Invalid user ID", query: "?user_id=invalid", status: http.StatusBadRequest},
+		{name: "Service error", query: "?user_id=" + userID.String(), err: assert.AnError, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			handler := NewHandler(mockService, &MockLogger{})
+
+			mockService.GetUserSummaryFunc = func(ctx context.Context, id uuid.UUID) (*UserCostSummary, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &UserCostSummary{UserID: id, MonthlyCost: 600, ActiveSubscriptions: 2, ComputedAt: time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)}, nil
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/summary"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.GetUserSummary(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.expected != "" {
+				assert.JSONEq(t, tt.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerRecomputeSummaries(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog,
+		WithAdminAuth(middleware.APIKey(middleware.APIKeyHeader, "admin-secret", nil, mockLog)))
+
+	called := false
+	mockService.RecomputeSummariesFunc = func(ctx context.Context) (*RecomputeSummariesResponse, error) {
+		called = true
+		return &RecomputeSummariesResponse{Users: 42}, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/recompute-summaries", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, called)
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/admin/recompute-summaries", nil)
+	req.Header.Set(middleware.APIKeyHeader, "admin-secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"users":42}}`, w.Body.String())
+}
+
+func TestHandlerGetServiceDiversity(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	var gotLimit, gotOffset int
+	mockService.GetServiceDiversityFunc = func(ctx context.Context, limit, offset int) (*Page[UserServices], error) {
+		gotLimit, gotOffset = limit, offset
+		return &Page[UserServices]{Items: []UserServices{{UserID: userID, DistinctServices: 3}}, Total: 1, Limit: limit, Offset: offset}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/diversity?limit=10", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetServiceDiversity(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 10, gotLimit)
+	assert.Equal(t, 0, gotOffset)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"data": {
+			"items": [{"user_id": "550e8400-e29b-41d4-a716-446655440000", "distinct_services": 3}],
+			"total": 1,
+			"limit": 10,
+			"offset": 0
+		}
+	}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/subscriptions/diversity?offset=ten", nil)
+	w = httptest.NewRecorder()
+
+	handler.GetServiceDiversity(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandlerGetBudgetStatus(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.GetBudgetStatusFunc = func(ctx context.Context) ([]BudgetStatus, error) {
+		return []BudgetStatus{
+			{ServiceName: "Hulu", MonthlyCap: 500, MonthlyCost: 650, OverBudget: true, Overage: 150},
+			{ServiceName: "Netflix", MonthlyCap: 800, MonthlyCost: 800},
+		}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/budget-status", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetBudgetStatus(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"data": [
+			{"service_name": "Hulu", "monthly_cap": 500, "monthly_cost": 650, "over_budget": true, "overage": 150},
+			{"service_name": "Netflix", "monthly_cap": 800, "monthly_cost": 800, "over_budget": false, "overage": 0}
+		]
+	}`, w.Body.String())
+
+	mockService.GetBudgetStatusFunc = func(ctx context.Context) ([]BudgetStatus, error) {
+		return nil, assert.AnError
+	}
+	w = httptest.NewRecorder()
+
+	handler.GetBudgetStatus(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandlerGetUsers(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	var gotLimit, gotOffset int
+	mockService.GetUsersFunc = func(ctx context.Context, limit, offset int) (*Page[UserSubscriptions], error) {
+		gotLimit, gotOffset = limit, offset
+		return &Page[UserSubscriptions]{Items: []UserSubscriptions{{UserID: userID, Subscriptions: 3}}, Total: 21, Limit: limit, Offset: offset}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/users?limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetUsers(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 10, gotLimit)
+	assert.Equal(t, 20, gotOffset)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"data": {
+			"items": [{"user_id": "550e8400-e29b-41d4-a716-446655440000", "subscriptions": 3}],
+			"total": 21,
+			"limit": 10,
+			"offset": 20
+		}
+	}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/subscriptions/users?limit=ten", nil)
+	w = httptest.NewRecorder()
+
+	handler.GetUsers(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandlerGetChanges(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	var gotSince string
+	mockService.GetChangesSinceFunc = func(ctx context.Context, since string) ([]Subscription, error) {
+		gotSince = since
+		if since == "" {
+			return nil, &ValidationError{Field: "since", Message: "since is required"}
+		}
+		return []Subscription{{ID: 7, ServiceName: "Netflix"}}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/changes?since=2025-06-01T12:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetChanges(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2025-06-01T12:00:00Z", gotSince)
+	assert.Contains(t, w.Body.String(), `"id":7`)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/subscriptions/changes", nil)
+	w = httptest.NewRecorder()
+
+	handler.GetChanges(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "since is required")
+
+	mockService.GetChangesSinceFunc = func(ctx context.Context, since string) ([]Subscription, error) {
+		return nil, assert.AnError
+	}
+	w = httptest.NewRecorder()
+
+	handler.GetChanges(w, httptest.NewRequest(http.MethodGet, "/v1/subscriptions/changes?since=2025-06-01T12:00:00Z", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestHandlerGetMeta(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.GetMetaFunc = func(ctx context.Context) *MetaResponse {
+		return &MetaResponse{
+			DateFormat:         "MM-YYYY",
+			ServiceNamePattern: "^[A-Za-z]+$",
+			SortFields:         []string{"id", "price"},
+			SortOrders:         []string{"asc", "desc"},
+			MaxPageLimit:       100,
+			MaxCostMonths:      120,
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/meta", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetMeta(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"data": {
+			"date_format": "MM-YYYY",
+			"service_name_pattern": "^[A-Za-z]+$",
+			"sort_fields": ["id", "price"],
+			"sort_orders": ["asc", "desc"],
+			"export_formats": ["csv", "jsonl", "table"],
+			"max_page_limit": 100,
+			"max_cost_months": 120
+		}
+	}`, w.Body.String())
+}
+
+func TestHandlerCreateSubscription_ExternalIDExists(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.CreateSubscriptionFunc = func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+		return nil, ErrExternalIDExists
+	}
+
+	body := `{"service_name":"Netflix","price":100,"user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2025","external_id":"crm-42"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handler.CreateSubscription(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"status":"error","data":null,"error":"external_id already exists"}`, w.Body.String())
+}
+
+func TestHandlerCreateSubscription_IfNoneMatch(t *testing.T) {
+	withExternalID := `{"service_name":"Netflix","price":100,"user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2025","external_id":"crm-42"}`
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		body        string
+		exists      bool
+		status      int
+		created     bool
+	}{
+		{name: "Unused external ID", ifNoneMatch: "*", body: withExternalID, status: http.StatusCreated, created: true},
+		{name: "Used external ID", ifNoneMatch: "*", body: withExternalID, exists: true, status: http.StatusPreconditionFailed},
+		{name: "Used external ID without precondition", body: withExternalID, exists: true, status: http.StatusConflict},
+		{
+			name:        "Missing external ID",
+			ifNoneMatch: "*",
+			body:        `{"service_name":"Netflix","price":100,"user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2025"}`,
+			status:      http.StatusBadRequest,
+		},
+		{name: "Entity tag", ifNoneMatch: `"abc"`, body: withExternalID, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			handler := NewHandler(mockService, &MockLogger{})
+
+			var created bool
+			mockService.CreateSubscriptionFunc = func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+				if tt.exists {
+					return nil, ErrExternalIDExists
+				}
+				created = true
+				return &Subscription{ID: 1, ExternalID: req.ExternalID}, nil
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewBufferString(tt.body))
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+
+			handler.CreateSubscription(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.created, created)
+			if tt.status == http.StatusPreconditionFailed {
+				assert.JSONEq(t, `{"status":"error","data":null,"error":"external_id already exists"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerCreateSubscription_PastStartDate(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.CreateSubscriptionFunc = func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+		return nil, ErrPastStartDate
+	}
+
+	body := `{"service_name":"Netflix","price":100,"user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2020"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handler.CreateSubscription(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"status":"error","data":null,"error":"start_date must not be before the current month"}`, w.Body.String())
+}
+
+func TestHandlerCreateSubscription_ComputedActive(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockRepo.CreateFunc = func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+		return &Subscription{ID: 1, ServiceName: req.ServiceName, Price: req.Price, UserID: req.UserID, StartDate: req.StartDate, EndDate: req.EndDate}, nil
+	}
+	svc := NewService(mockRepo, &MockLogger{}).(*service)
+	svc.now = func() time.Time { return time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC) }
+	handler := NewHandler(svc, &MockLogger{})
+
+	tests := []struct {
+		name   string
+		body   string
+		active bool
+	}{
+		{name: "Already started", body: `{"service_name":"Netflix","price":100,"user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2025","end_date":"12-2025"}`, active: true},
+		{name: "Ended", body: `{"service_name":"Netflix","price":100,"user_id":"550e8400-e29b-41d4-a716-446655440000","start_date":"01-2025","end_date":"05-2025"}`, active: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.CreateSubscription(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			var resp struct {
+				Data Subscription `json:"data"`
+			}
+			if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp)) {
+				assert.Equal(t, 1, resp.Data.ID)
+				assert.Equal(t, tt.active, resp.Data.Active)
+			}
+		})
+	}
+}
+
+func TestGetSubscriptions_ExternalID(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	externalID := "crm-42"
+	mockService.GetSubscriptionByExternalIDFunc = func(ctx context.Context, id string) (*Subscription, error) {
+		if id != externalID {
+			return nil, ErrNotFound
+		}
+		return &Subscription{ID: 7, ServiceName: "Netflix", ExternalID: &externalID}, nil
+	}
+	mockService.ListSubscriptionsFunc = func(ctx context.Context, sort Sort) (*SubscriptionList, error) {
+		t.Fatal("the listing must not be queried")
+		return nil, nil
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []int
+	}{
+		{name: "Found", query: "crm-42", expected: []int{7}},
+		{name: "Not found", query: "crm-43", expected: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions?external_id="+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetSubscriptions(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response struct {
+				Data []Subscription `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			ids := []int{}
+			for _, sub := range response.Data {
+				ids = append(ids, sub.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestHandlerGetCostByPeriod_IncludeIDs(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+		return &CostResponse{TotalCost: 200, Count: 2}, nil
+	}
+	mockService.GetCostWithIDsFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error) {
+		return &CostWithIDsResponse{CostResponse: CostResponse{TotalCost: 200, Count: 2}, SubscriptionIDs: []int{3, 5}}, nil
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		code     int
+		expected string
+	}{
+		{name: "Off by default", query: "", code: http.StatusOK, expected: `{"status":"success","data":{"total_cost":200,"count":2}}`},
+		{name: "Disabled", query: "&include_ids=false", code: http.StatusOK, expected: `{"status":"success","data":{"total_cost":200,"count":2}}`},
+		{name: "Enabled", query: "&include_ids=true", code: http.StatusOK, expected: `{"status":"success","data":{"total_cost":200,"count":2,"subscription_ids":[3,5]}}`},
+		{name: "Invalid", query: "&include_ids=yes", code: http.StatusBadRequest, expected: `{"status":"error","data":null,"error":"Invalid include_ids"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions/cost?start_date=01-2025"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetCostByPeriod(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestHandlerQueryCost(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	var got CostQuery
+	mockService.GetCostByQueryFunc = func(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error) {
+		got = query
+		cost := &CostWithIDsResponse{CostResponse: CostResponse{TotalCost: 210, Count: 3}}
+		if query.IncludeIDs {
+			cost.SubscriptionIDs = []int{1, 2, 4}
+		}
+		return cost, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	query := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions/cost/query", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := query(`{
+		"start_date": "01-2025",
+		"end_date": "12-2025",
+		"user_ids": ["550e8400-e29b-41d4-a716-446655440000", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"],
+		"service_names": ["Netflix", "Spotify", "Yandex Plus"]
+	}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"total_cost":210,"count":3}}`, w.Body.String())
+	assert.Equal(t, CostQuery{
+		StartDate:    "01-2025",
+		EndDate:      "12-2025",
+		UserIDs:      []uuid.UUID{uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")},
+		ServiceNames: []string{"Netflix", "Spotify", "Yandex Plus"},
+	}, got)
+
+	w = query(`{"start_date":"01-2025","user_ids":["550e8400-e29b-41d4-a716-446655440000"],"include_ids":true}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"total_cost":210,"count":3,"subscription_ids":[1,2,4]}}`, w.Body.String())
+
+	w = query(`{"start_date":"01-2025","user_ids":["not-a-uuid"]}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"status":"error","data":null,"error":"Invalid JSON"}`, w.Body.String())
+
+	mockService.GetCostByQueryFunc = func(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error) {
+		return nil, newValidationError("start_date", "at least one date parameter is required")
+	}
+
+	w = query(`{"service_names":["Netflix"]}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"status":"error","data":null,"error":"at least one date parameter is required"}`, w.Body.String())
+}
+
+func TestHandlerPreviewCostChange(t *testing.T) {
+	mockService := &MockService{}
+	handler := NewHandler(mockService, &MockLogger{})
+
+	var got CostPreviewRequest
+	mockService.PreviewCostChangeFunc = func(ctx context.Context, req CostPreviewRequest) (*CostPreviewResponse, error) {
+		got = req
+		return &CostPreviewResponse{TotalCost: 350, ProjectedCost: 410, Delta: 60, Count: 3, Changed: 2}, nil
+	}
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	body := `{"filter":{"start_date":"01-2025","service_names":["Netflix"]},"change":{"service_name":"Netflix","price":130}}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions/cost/preview", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"total_cost":350,"projected_cost":410,"delta":60,"count":3,"changed":2}}`, w.Body.String())
+	assert.Equal(t, CostPreviewRequest{
+		Filter: CostQuery{StartDate: "01-2025", ServiceNames: []string{"Netflix"}},
+		Change: PriceChange{ServiceName: "Netflix", Price: 130},
+	}, got)
+}
+
+func TestGetSubscriptions_Truncated(t *testing.T) {
+	mockService := &MockService{}
+	mockLog := &MockLogger{}
+	handler := NewHandler(mockService, mockLog)
+
+	mockService.ListSubscriptionsFunc = func(ctx context.Context, sort Sort) (*SubscriptionList, error) {
+		return &SubscriptionList{Subscriptions: []Subscription{{ID: 1}, {ID: 2}}, Truncated: true}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetSubscriptions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response Response
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	assert.True(t, response.Truncated)
+	assert.Contains(t, response.Warning, "only the first 2 subscriptions are listed")
+	assert.Len(t, response.Data, 2)
+}
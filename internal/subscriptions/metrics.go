@@ -0,0 +1,16 @@
+package subscriptions
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var validationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "subscriptions_validation_failures_total",
+	Help: "Number of rejected requests by the field that failed validation.",
+}, []string{"field"})
+
+var budgetExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "subscriptions_budget_exceeded_total",
+	Help: "Number of times the monthly cost of a service crossed its budget cap.",
+}, []string{"service_name"})
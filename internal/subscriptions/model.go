@@ -1,45 +1,433 @@
 package subscriptions
 
 import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Subscription is a row of the subscriptions table. Nullable columns map to
+// pointer fields, as scanning NULL into a plain field fails. ReferenceCode is
+// generated by the database on insert, in the form SUB-XXXXXXXXXXXX.
 type Subscription struct {
-	ID          int       `json:"id"`
-	ServiceName string    `json:"service_name"`
-	Price       int       `json:"price"`
-	UserID      uuid.UUID `json:"user_id"`
-	StartDate   string    `json:"start_date"`
-	EndDate     *string   `json:"end_date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            int       `json:"id" db:"id"`
+	ServiceName   string    `json:"service_name" db:"service_name"`
+	Price         int       `json:"price" db:"price"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	StartDate     string    `json:"start_date" db:"start_date"`
+	EndDate       *string   `json:"end_date" db:"end_date"`
+	RenewedFromID *int      `json:"renewed_from_id" db:"renewed_from_id"`
+	ExternalID    *string   `json:"external_id" db:"external_id"`
+	ReferenceCode string    `json:"reference_code" db:"reference_code"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	// Active and NextBillingDate are computed by the service and not stored,
+	// see isActive and nextBillingDate.
+	Active          bool    `json:"active" db:"-"`
+	NextBillingDate *string `json:"next_billing_date" db:"-"`
+}
+
+// setComputed sets the fields computed as of month, the first day of a month.
+func (s *Subscription) setComputed(month time.Time) {
+	s.Active = s.isActive(month)
+	s.NextBillingDate = s.nextBillingDate(month)
+}
+
+// isActive reports whether the subscription has not ended before month, the
+// first day of a month. It follows the end_date predicate of the cost filter:
+// open-ended subscriptions are always active.
+func (s Subscription) isActive(month time.Time) bool {
+	if s.EndDate == nil {
+		return true
+	}
+
+	end, err := time.Parse(monthLayout, *s.EndDate)
+	if err != nil {
+		return false
+	}
+	return !end.Before(month)
+}
+
+// nextBillingDate returns the first billing month after month, the first day of
+// a month. Prices are monthly, as the cost queries assume, so a started
+// subscription is next billed the following month and one not started yet in
+// its start month. It is nil when no billing is left before end_date,
+// including for expired subscriptions.
+func (s Subscription) nextBillingDate(month time.Time) *string {
+	start, err := time.Parse(monthLayout, s.StartDate)
+	if err != nil {
+		return nil
+	}
+
+	next := start
+	if !start.After(month) {
+		next = month.AddDate(0, 1, 0)
+	}
+
+	if s.EndDate != nil {
+		end, err := time.Parse(monthLayout, *s.EndDate)
+		if err != nil || next.After(end) {
+			return nil
+		}
+	}
+
+	date := next.Format(monthLayout)
+	return &date
 }
 
+// flatRecordHeader names the columns of toFlatRecord, in the same order.
+var flatRecordHeader = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "renewed_from_id", "external_id", "reference_code", "created_at", "updated_at"}
+
+// toFlatRecord renders the subscription as one row of text cells for tabular
+// exports. Columns follow flatRecordHeader; nil values become empty cells.
+func (s Subscription) toFlatRecord() []string {
+	endDate := ""
+	if s.EndDate != nil {
+		endDate = *s.EndDate
+	}
+	renewedFromID := ""
+	if s.RenewedFromID != nil {
+		renewedFromID = strconv.Itoa(*s.RenewedFromID)
+	}
+	externalID := ""
+	if s.ExternalID != nil {
+		externalID = *s.ExternalID
+	}
+
+	return []string{
+		strconv.Itoa(s.ID),
+		s.ServiceName,
+		strconv.Itoa(s.Price),
+		s.UserID.String(),
+		s.StartDate,
+		endDate,
+		renewedFromID,
+		externalID,
+		s.ReferenceCode,
+		s.CreatedAt.Format(time.RFC3339),
+		s.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// CreateSubscriptionRequest describes a new subscription. ExternalID is an
+// optional reference to the subscription in another system, unique across
+// subscriptions.
 type CreateSubscriptionRequest struct {
 	ServiceName string    `json:"service_name"`
-	Price       int       `json:"price"`
+	Price       int       `json:"price" extensions:"x-numeric-string"`
 	UserID      uuid.UUID `json:"user_id"`
 	StartDate   string    `json:"start_date"`
-	EndDate     *string   `json:"end_date,omitempty"`
+	EndDate     *string   `json:"end_date,omitempty" extensions:"x-nullable"`
+	ExternalID  *string   `json:"external_id,omitempty" extensions:"x-nullable"`
 }
 
+// UpdateSubscriptionRequest replaces the fields of a subscription. An omitted
+// ExternalID keeps the current one.
 type UpdateSubscriptionRequest struct {
 	ServiceName string    `json:"service_name"`
-	Price       int       `json:"price"`
+	Price       int       `json:"price" extensions:"x-numeric-string"`
 	UserID      uuid.UUID `json:"user_id"`
 	StartDate   string    `json:"start_date"`
-	EndDate     *string   `json:"end_date,omitempty"`
+	EndDate     *string   `json:"end_date,omitempty" extensions:"x-nullable"`
+	ExternalID  *string   `json:"external_id,omitempty" extensions:"x-nullable"`
+}
+
+// RenewSubscriptionRequest describes the period of a renewal. Service, price and
+// user are copied from the renewed subscription.
+type RenewSubscriptionRequest struct {
+	StartDate string  `json:"start_date"`
+	EndDate   *string `json:"end_date,omitempty" extensions:"x-nullable"`
+}
+
+// UnmarshalJSON accepts price both as a JSON number and as a numeric string
+// and treats an empty end_date the same as an omitted one.
+func (r *CreateSubscriptionRequest) UnmarshalJSON(data []byte) error {
+	type alias CreateSubscriptionRequest
+	aux := struct {
+		*alias
+		Price json.RawMessage `json:"price"`
+	}{alias: (*alias)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	price, err := parsePrice(aux.Price)
+	if err != nil {
+		return err
+	}
+	r.Price = price
+	r.EndDate = normalizeEndDate(r.EndDate)
+
+	return nil
+}
+
+// UnmarshalJSON accepts price both as a JSON number and as a numeric string
+// and treats an empty end_date the same as an omitted one.
+func (r *UpdateSubscriptionRequest) UnmarshalJSON(data []byte) error {
+	return (*CreateSubscriptionRequest)(r).UnmarshalJSON(data)
+}
+
+// UnmarshalJSON treats an empty end_date the same as an omitted one.
+func (r *RenewSubscriptionRequest) UnmarshalJSON(data []byte) error {
+	type alias RenewSubscriptionRequest
+	if err := json.Unmarshal(data, (*alias)(r)); err != nil {
+		return err
+	}
+
+	r.EndDate = normalizeEndDate(r.EndDate)
+	return nil
+}
+
+// normalizeEndDate maps an empty end date to nil, meaning an open-ended subscription.
+func normalizeEndDate(endDate *string) *string {
+	if endDate != nil && *endDate == "" {
+		return nil
+	}
+	return endDate
+}
+
+func parsePrice(raw json.RawMessage) (int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	var price int
+	if err := json.Unmarshal(raw, &price); err == nil {
+		return price, nil
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err != nil {
+		return 0, fmt.Errorf("price must be a number")
+	}
+
+	price, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("price must be a number")
+	}
+
+	return price, nil
+}
+
+// Sort orders a subscription listing. Field is a sort_by value such as "price"
+// and Order is "asc" or "desc"; the zero value lists the newest first.
+type Sort struct {
+	Field string
+	Order string
+}
+
+// SubscriptionFilter narrows subscriptions by period, user and service. Empty
+// fields are not applied.
+type SubscriptionFilter struct {
+	StartDate   string
+	EndDate     string
+	UserID      *uuid.UUID
+	ServiceName *string
+}
+
+// CostQuery is the body of POST /subscriptions/cost/query: the period of
+// GET /subscriptions/cost with lists of users and services, too long for a
+// query string. Empty lists are not applied. IncludeIDs lists the IDs of the
+// included subscriptions like include_ids.
+type CostQuery struct {
+	StartDate    string      `json:"start_date"`
+	EndDate      string      `json:"end_date,omitempty"`
+	UserIDs      []uuid.UUID `json:"user_ids,omitempty"`
+	ServiceNames []string    `json:"service_names,omitempty"`
+	IncludeIDs   bool        `json:"include_ids,omitempty"`
 }
 
+// CostPreviewRequest is the body of POST /subscriptions/cost/preview: the filter
+// of POST /subscriptions/cost/query, whose IncludeIDs is not applied, and a
+// price change to preview.
+type CostPreviewRequest struct {
+	Filter CostQuery   `json:"filter"`
+	Change PriceChange `json:"change"`
+}
+
+// PriceChange is a proposed monthly price for the subscriptions to a service.
+type PriceChange struct {
+	ServiceName string `json:"service_name"`
+	Price       int    `json:"price"`
+}
+
+// ServiceFilter narrows the distinct service names. Prefix keeps the names
+// starting with it and Query those containing it, ranked exact match first,
+// then prefix matches, then the rest. Both are case-insensitive; empty fields
+// are not applied.
+type ServiceFilter struct {
+	Prefix string
+	Query  string
+}
+
+// CostResponse is the cost of the subscriptions matching a filter. SUM(price) is
+// a bigint in PostgreSQL, so TotalCost is an int64 whatever the platform.
 type CostResponse struct {
-	TotalCost int `json:"total_cost"`
-	Count     int `json:"count"`
+	TotalCost int64 `json:"total_cost"`
+	Count     int   `json:"count"`
+}
+
+// CostVersion identifies the subscriptions a cost is computed from, over the
+// resolved period. Count drops on a delete and LastModified advances on any
+// other write, so the version changes whenever the cost may have.
+type CostVersion struct {
+	StartDate    string
+	EndDate      string
+	Count        int
+	LastModified *time.Time
+}
+
+// ETag returns the entity tag of the cost of the version.
+func (v CostVersion) ETag() string {
+	var modified int64
+	if v.LastModified != nil {
+		modified = v.LastModified.UnixNano()
+	}
+
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s|%s|%d|%d", v.StartDate, v.EndDate, v.Count, modified)
+	return fmt.Sprintf(`"%x"`, h.Sum64())
 }
 
+// CostWithIDsResponse is a CostResponse listing the IDs of the subscriptions
+// included in the total.
+type CostWithIDsResponse struct {
+	CostResponse
+	SubscriptionIDs []int `json:"subscription_ids"`
+}
+
+// CostPreviewResponse is the cost of the subscriptions matching a filter before
+// and after a price change, of which Changed subscriptions are affected.
+type CostPreviewResponse struct {
+	TotalCost     int64 `json:"total_cost"`
+	ProjectedCost int64 `json:"projected_cost"`
+	Delta         int64 `json:"delta"`
+	Count         int   `json:"count"`
+	Changed       int   `json:"changed"`
+}
+
+// UserSubscriptions is a user together with the number of their subscriptions.
+type UserSubscriptions struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Subscriptions int       `json:"subscriptions"`
+}
+
+// UserServices is a user together with the number of distinct services they
+// subscribe to.
+type UserServices struct {
+	UserID           uuid.UUID `json:"user_id"`
+	DistinctServices int       `json:"distinct_services"`
+}
+
+type DateRangeResponse struct {
+	MinStartDate *string `json:"min_start_date"`
+	MaxEndDate   *string `json:"max_end_date"`
+}
+
+// StatsResponse holds aggregates over all subscriptions. Active subscriptions
+// cover the current month; monthly_cost sums their prices.
+type StatsResponse struct {
+	Subscriptions       int       `json:"subscriptions"`
+	ActiveSubscriptions int       `json:"active_subscriptions"`
+	Users               int       `json:"users"`
+	Services            int       `json:"services"`
+	MonthlyCost         int       `json:"monthly_cost"`
+	ComputedAt          time.Time `json:"computed_at"`
+}
+
+// UserCostSummary is the precomputed cost of a user's subscriptions active in
+// the month of computed_at. It is only as fresh as the last recompute.
+type UserCostSummary struct {
+	UserID              uuid.UUID `json:"user_id"`
+	MonthlyCost         int       `json:"monthly_cost"`
+	ActiveSubscriptions int       `json:"active_subscriptions"`
+	ComputedAt          time.Time `json:"computed_at"`
+}
+
+type RecomputeSummariesResponse struct {
+	Users int64 `json:"users"`
+}
+
+// BudgetStatus compares the monthly cost of a service, the sum of the prices of
+// its subscriptions active in the current month, with its budget cap. Overage
+// is the cost above the cap, zero unless over budget.
+type BudgetStatus struct {
+	ServiceName string `json:"service_name"`
+	MonthlyCap  int    `json:"monthly_cap"`
+	MonthlyCost int    `json:"monthly_cost"`
+	OverBudget  bool   `json:"over_budget"`
+	Overage     int    `json:"overage"`
+}
+
+type DeleteUserSubscriptionsResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+type CancelUserSubscriptionsResponse struct {
+	Cancelled int64 `json:"cancelled"`
+}
+
+// ValidationResponse is the result of validating a subscription payload. Errors
+// lists every violation and is omitted when the payload is valid.
+type ValidationResponse struct {
+	Valid  bool               `json:"valid"`
+	Errors []*ValidationError `json:"errors,omitempty"`
+}
+
+// MetaResponse lists the values accepted by the API, so that clients do not
+// have to hardcode them. MaxCostMonths is 0 when the cost period is unbounded.
+type MetaResponse struct {
+	DateFormat         string   `json:"date_format"`
+	ServiceNamePattern string   `json:"service_name_pattern"`
+	SortFields         []string `json:"sort_fields"`
+	SortOrders         []string `json:"sort_orders"`
+	ExportFormats      []string `json:"export_formats"`
+	MaxPageLimit       int      `json:"max_page_limit"`
+	MaxCostMonths      int      `json:"max_cost_months"`
+}
+
+// OperationDescription describes an operation supported on a resource, as
+// returned by OPTIONS requests.
+type OperationDescription struct {
+	Method      string   `json:"method"`
+	Description string   `json:"description"`
+	Parameters  []string `json:"parameters,omitempty"`
+}
+
+// Response is the JSON envelope of every endpoint. data is always present and
+// is null when there is nothing to return, e.g. on errors; error is only set on
+// errors. Endpoints without a body, such as delete, answer 204 instead.
+// code is a machine-readable error code, set on some errors such as not_found.
+// truncated and warning are only set when a listing was cut short.
 type Response struct {
-	Status string      `json:"status"`
-	Data   any `json:"data,omitempty"`
-	Error  string      `json:"error,omitempty"`
+	Status    string `json:"status"`
+	Data      any    `json:"data"`
+	Code      string `json:"code,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Warning   string `json:"warning,omitempty"`
+}
+
+// Page is one page of a paginated listing. Total counts the items of all pages.
+// NextCursor is the offset of the next page, to pass as offset, and is omitted
+// on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// SubscriptionList is a subscription listing. Truncated reports that more
+// subscriptions matched than were returned.
+type SubscriptionList struct {
+	Subscriptions []Subscription
+	Truncated     bool
 }
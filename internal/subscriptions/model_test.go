@@ -0,0 +1,149 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSubscriptionRequest_UnmarshalPrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		price   int
+		wantErr bool
+	}{
+		{
+			name:  "Number",
+			body:  `{"service_name":"Netflix","price":100}`,
+			price: 100,
+		},
+		{
+			name:  "Numeric string",
+			body:  `{"service_name":"Netflix","price":"100"}`,
+			price: 100,
+		},
+		{
+			name:    "Non-numeric string",
+			body:    `{"service_name":"Netflix","price":"abc"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateSubscriptionRequest
+			err := json.Unmarshal([]byte(tt.body), &req)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.price, req.Price)
+			assert.Equal(t, "Netflix", req.ServiceName)
+		})
+	}
+}
+
+func TestUpdateSubscriptionRequest_UnmarshalPrice(t *testing.T) {
+	var req UpdateSubscriptionRequest
+	err := json.Unmarshal([]byte(`{"price":"150"}`), &req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 150, req.Price)
+}
+
+func TestSubscription_MarshalPriceAsNumber(t *testing.T) {
+	body, err := json.Marshal(Subscription{Price: 100})
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"price":100`)
+}
+
+
+func TestRequests_EmptyEndDateEqualsOmitted(t *testing.T) {
+	const withEmpty = `{"service_name":"Netflix","price":100,"start_date":"01-2025","end_date":""}`
+	const omitted = `{"service_name":"Netflix","price":100,"start_date":"01-2025"}`
+
+	decode := func(t *testing.T, body string, v any) {
+		t.Helper()
+		if err := json.Unmarshal([]byte(body), v); err != nil {
+			t.Fatalf("failed to decode %s: %v", body, err)
+		}
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		var a, b CreateSubscriptionRequest
+		decode(t, withEmpty, &a)
+		decode(t, omitted, &b)
+		assert.Nil(t, a.EndDate)
+		assert.Equal(t, b, a)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		var a, b UpdateSubscriptionRequest
+		decode(t, withEmpty, &a)
+		decode(t, omitted, &b)
+		assert.Nil(t, a.EndDate)
+		assert.Equal(t, b, a)
+	})
+
+	t.Run("Renew", func(t *testing.T) {
+		var a, b RenewSubscriptionRequest
+		decode(t, `{"start_date":"01-2026","end_date":""}`, &a)
+		decode(t, `{"start_date":"01-2026"}`, &b)
+		assert.Nil(t, a.EndDate)
+		assert.Equal(t, b, a)
+	})
+}
+func TestSubscription_ToFlatRecord(t *testing.T) {
+	endDate := "12-2025"
+	renewedFromID := 7
+	externalID := "ext-42"
+	created := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	sub := Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       100,
+		UserID:      uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+		StartDate:   "01-2025",
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+
+	t.Run("Without end date", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"1", "Netflix", "100", "550e8400-e29b-41d4-a716-446655440000", "01-2025", "", "", "", "",
+			"2025-01-15T10:00:00Z", "2025-01-15T10:00:00Z",
+		}, sub.toFlatRecord())
+	})
+
+	t.Run("With end date", func(t *testing.T) {
+		sub := sub
+		sub.EndDate = &endDate
+		sub.RenewedFromID = &renewedFromID
+
+		assert.Equal(t, []string{
+			"1", "Netflix", "100", "550e8400-e29b-41d4-a716-446655440000", "01-2025", "12-2025", "7", "", "",
+			"2025-01-15T10:00:00Z", "2025-01-15T10:00:00Z",
+		}, sub.toFlatRecord())
+	})
+
+	t.Run("With external references", func(t *testing.T) {
+		sub := sub
+		sub.ExternalID = &externalID
+		sub.ReferenceCode = "SUB-0001"
+
+		assert.Equal(t, []string{
+			"1", "Netflix", "100", "550e8400-e29b-41d4-a716-446655440000", "01-2025", "", "", "ext-42", "SUB-0001",
+			"2025-01-15T10:00:00Z", "2025-01-15T10:00:00Z",
+		}, sub.toFlatRecord())
+	})
+
+	assert.Len(t, sub.toFlatRecord(), len(flatRecordHeader))
+}
@@ -2,47 +2,215 @@ package subscriptions
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/n-korel/user-subscriptions-api/internal/logger"
 )
 
 type SubscriptionRepository interface {
-	GetAll(ctx context.Context) ([]Subscription, error)
+	GetAll(ctx context.Context, sort Sort, limit int) ([]Subscription, error)
 	GetByID(ctx context.Context, id int) (*Subscription, error)
 	Create(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error)
 	Update(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error)
-	Delete(ctx context.Context, id int) error
-	GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int, int, error)
+	Delete(ctx context.Context, id int) (*Subscription, error)
+	GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error)
+	GetCostByQuery(ctx context.Context, query CostQuery) (int64, int, error)
+	GetCostByQueryWithIDs(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error)
+	GetCostVersion(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error)
+	GetCostWithIDs(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error)
+	GetServices(ctx context.Context, filter ServiceFilter, limit, offset int) ([]string, int, error)
+	GetDateRange(ctx context.Context, userID *uuid.UUID) (*DateRangeResponse, error)
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	CancelByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	Renew(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error)
+	Export(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error)
+	GetSubscribers(ctx context.Context, serviceName string, limit, offset int) ([]uuid.UUID, int, error)
+	GetStats(ctx context.Context) (*StatsResponse, error)
+	GetUsers(ctx context.Context, limit, offset int) ([]UserSubscriptions, int, error)
+	GetServiceDiversity(ctx context.Context, limit, offset int) ([]UserServices, int, error)
+	GetBudgetCosts(ctx context.Context) ([]BudgetStatus, error)
+	GetByExternalID(ctx context.Context, externalID string) (*Subscription, error)
+	GetChangedSince(ctx context.Context, since time.Time) ([]Subscription, error)
+	RecomputeSummaries(ctx context.Context) (int64, error)
+	GetUserSummary(ctx context.Context, userID uuid.UUID) (*UserCostSummary, error)
+}
+
+var ErrNotFound = errors.New("subscription not found")
+
+// ErrExternalIDExists is returned by Create and Update when another
+// subscription already has the external ID.
+var ErrExternalIDExists = errors.New("external_id already exists")
+
+// ErrUserIDImmutable is returned by Update when the request moves the
+// subscription to another user.
+var ErrUserIDImmutable = errors.New("user_id cannot be changed")
+
+// ErrSummaryNotFound is returned by GetUserSummary for a user without active
+// subscriptions at the last recompute.
+var ErrSummaryNotFound = errors.New("summary not found")
+
+// ErrUnavailable is wrapped around errors of statements the database refused
+// to run for lack of connections. The request can be retried later.
+var ErrUnavailable = errors.New("database is unavailable")
+
+// maxCreateAttempts bounds how many times Create runs an insert that failed
+// with a retryable error.
+const maxCreateAttempts = 3
+
+// PostgreSQL error codes of conflicts that are resolved by retrying.
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// tooManyConnections is the PostgreSQL error code of a connection refused
+// because the server is at its connection limit.
+const tooManyConnections = "53300"
+
+// uniqueViolation is the PostgreSQL error code of a duplicate key, and
+// externalIDIndex the unique index it is reported for on a reused external ID.
+const (
+	uniqueViolation = "23505"
+	externalIDIndex = "idx_subscriptions_external_id"
+)
+
+// referenceCodeIndex is the unique index of the reference codes generated by
+// the database. A clash of two random codes is resolved by retrying.
+const referenceCodeIndex = "idx_subscriptions_reference_code"
+
+// subscriptionColumns lists the columns selected for a Subscription. Rows are
+// mapped to the struct by the db tags, so a new column is added here and on the
+// struct only.
+const subscriptionColumns = "id, service_name, price, user_id, start_date, end_date, renewed_from_id, external_id, reference_code, created_at, updated_at"
+
+// DB is the part of *pgxpool.Pool used by the repository.
+type DB interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
 type repository struct {
-	db  *pgxpool.Pool
+	db     DB
+	readDB DB
+	log    logger.LoggerInterface
+
+	// readDBHealthy reports whether readDB can serve reads; nil means always.
+	readDBHealthy func() bool
+}
+
+type RepositoryOption func(*repository)
+
+// WithReadDB routes read-only queries to db, typically a read replica. Writes
+// and reads that are part of a write always use the primary.
+func WithReadDB(db DB) RepositoryOption {
+	return func(r *repository) {
+		r.readDB = db
+	}
+}
+
+// WithReadDBFallback routes reads to the primary while healthy reports false,
+// e.g. while the read replica set by WithReadDB is unreachable.
+func WithReadDBFallback(healthy func() bool) RepositoryOption {
+	return func(r *repository) {
+		r.readDBHealthy = healthy
+	}
+}
+
+func NewRepository(db DB, log logger.LoggerInterface, opts ...RepositoryOption) SubscriptionRepository {
+	r := &repository{db: db, readDB: db, log: log}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.db = availabilityDB{DB: r.db, log: log}
+	r.readDB = availabilityDB{DB: r.readDB, log: log}
+	return r
+}
+
+// availabilityDB wraps ErrUnavailable around the errors of a DB refusing a
+// connection, see checkAvailable. Connections are acquired by the statements
+// run on the pool, so the errors of later calls on their rows and
+// transactions are left as they are.
+type availabilityDB struct {
+	DB
 	log logger.LoggerInterface
 }
 
-func NewRepository(db *pgxpool.Pool, log logger.LoggerInterface) SubscriptionRepository {
-	return &repository{db: db, log: log}
+func (db availabilityDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := db.DB.Query(ctx, sql, args...)
+	return rows, db.checkAvailable(err)
+}
+
+func (db availabilityDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return availabilityRow{Row: db.DB.QueryRow(ctx, sql, args...), db: db}
+}
+
+func (db availabilityDB) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	tag, err := db.DB.Exec(ctx, sql, arguments...)
+	return tag, db.checkAvailable(err)
+}
+
+func (db availabilityDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := db.DB.Begin(ctx)
+	return tx, db.checkAvailable(err)
+}
+
+// checkAvailable wraps ErrUnavailable around err when the database is out of
+// connections, logging a warning.
+func (db availabilityDB) checkAvailable(err error) error {
+	if !hasCode(err, tooManyConnections) {
+		return err
+	}
+	db.log.Warn("Database connection limit reached", map[string]any{"error": err})
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
+
+// availabilityRow is the pgx.Row of availabilityDB.QueryRow, whose statement
+// runs on Scan.
+type availabilityRow struct {
+	pgx.Row
+	db availabilityDB
 }
 
-func (r *repository) GetAll(ctx context.Context) ([]Subscription, error) {
-	rows, err := r.db.Query(ctx, "SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at FROM subscriptions ORDER BY created_at DESC")
+func (row availabilityRow) Scan(dest ...any) error {
+	return row.db.checkAvailable(row.Row.Scan(dest...))
+}
+
+// reader returns the database serving read-only queries.
+func (r *repository) reader() DB {
+	if r.readDBHealthy != nil && !r.readDBHealthy() {
+		return r.db
+	}
+	return r.readDB
+}
+
+// GetAll returns subscriptions in the sort order, at most limit of them unless
+// limit is zero.
+func (r *repository) GetAll(ctx context.Context, sort Sort, limit int) ([]Subscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM subscriptions " + orderBy(sort)
+	var args []any
+	if limit > 0 {
+		query += " LIMIT $1"
+		args = append(args, limit)
+	}
+
+	rows, err := r.reader().Query(ctx, query, args...)
 	if err != nil {
 		r.log.Error("Failed to query subscriptions", map[string]any{"error": err})
 		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
 	}
-	defer rows.Close()
 
-	subscriptions := make([]Subscription, 0)
-	for rows.Next() {
-		var sub Subscription
-		if err := rows.Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
-			r.log.Error("Failed to scan subscription", map[string]any{"error": err})
-			return nil, fmt.Errorf("failed to scan subscription: %w", err)
-		}
-		subscriptions = append(subscriptions, sub)
+	subscriptions, err := collectSubscriptions(rows)
+	if err != nil {
+		r.log.Error("Failed to scan subscription", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to scan subscription: %w", err)
 	}
 
 	r.log.Info("Retrieved all subscriptions", map[string]any{"count": len(subscriptions)})
@@ -50,76 +218,336 @@ func (r *repository) GetAll(ctx context.Context) ([]Subscription, error) {
 }
 
 func (r *repository) GetByID(ctx context.Context, id int) (*Subscription, error) {
-	var sub Subscription
-	err := r.db.QueryRow(ctx, "SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at FROM subscriptions WHERE id = $1", id).
-		Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate, &sub.CreatedAt, &sub.UpdatedAt)
-	if err != nil {
+	sub, err := querySubscription(ctx, r.reader(), "SELECT "+subscriptionColumns+" FROM subscriptions WHERE id = $1", id)
+	if errors.Is(err, pgx.ErrNoRows) {
 		r.log.Warn("Subscription not found", map[string]any{"id": id})
-		return nil, fmt.Errorf("subscription not found: %w", err)
+		return nil, ErrNotFound
 	}
-	return &sub, nil
+	if err != nil {
+		r.log.Error("Failed to get subscription", map[string]any{"error": err, "id": id})
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// GetByExternalID returns the subscription with the given external ID.
+func (r *repository) GetByExternalID(ctx context.Context, externalID string) (*Subscription, error) {
+	sub, err := querySubscription(ctx, r.reader(), "SELECT "+subscriptionColumns+" FROM subscriptions WHERE external_id = $1", externalID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.log.Warn("Subscription not found", map[string]any{"external_id": externalID})
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to get subscription by external ID", map[string]any{"error": err, "external_id": externalID})
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
 }
 
+// Create inserts a subscription. An insert failing with a retryable error,
+// such as a serialization failure, is retried up to maxCreateAttempts times.
 func (r *repository) Create(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
-	var sub Subscription
-	err := r.db.QueryRow(ctx,
-		"INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date) VALUES ($1, $2, $3, $4, $5) RETURNING id, service_name, price, user_id, start_date, end_date, created_at, updated_at",
-		req.ServiceName, req.Price, req.UserID, req.StartDate, req.EndDate,
-	).Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate, &sub.CreatedAt, &sub.UpdatedAt)
+	var sub *Subscription
+	var err error
+	for attempt := 1; attempt <= maxCreateAttempts; attempt++ {
+		sub, err = querySubscription(ctx, r.db,
+			"INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date, external_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING "+subscriptionColumns,
+			req.ServiceName, req.Price, req.UserID, req.StartDate, req.EndDate, req.ExternalID,
+		)
+		if !isRetryable(err) || attempt == maxCreateAttempts {
+			break
+		}
+		r.log.Warn("Retrying subscription create", map[string]any{"error": err, "attempt": attempt})
+	}
 
+	if isExternalIDConflict(err) {
+		r.log.Warn("Subscription external ID already exists", map[string]any{"external_id": *req.ExternalID})
+		return nil, ErrExternalIDExists
+	}
 	if err != nil {
 		r.log.Error("Failed to create subscription", map[string]any{"error": err, "service": req.ServiceName})
 		return nil, fmt.Errorf("failed to create subscription: %w", err)
 	}
 
 	r.log.Info("Subscription created", map[string]any{"id": sub.ID, "service": req.ServiceName, "user_id": req.UserID})
-	return &sub, nil
+	return sub, nil
 }
 
 func (r *repository) Update(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error) {
-	var sub Subscription
-	err := r.db.QueryRow(ctx,
-		"UPDATE subscriptions SET service_name=$1, price=$2, user_id=$3, start_date=$4, end_date=$5, updated_at=CURRENT_TIMESTAMP WHERE id=$6 RETURNING id, service_name, price, user_id, start_date, end_date, created_at, updated_at",
-		req.ServiceName, req.Price, req.UserID, req.StartDate, req.EndDate, id,
-	).Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate, &sub.CreatedAt, &sub.UpdatedAt)
+	sub, err := querySubscription(ctx, r.db,
+		"UPDATE subscriptions SET service_name=$1, price=$2, start_date=$4, end_date=$5, external_id=COALESCE($6, external_id), updated_at=CURRENT_TIMESTAMP WHERE id=$7 AND user_id=$3 RETURNING "+subscriptionColumns,
+		req.ServiceName, req.Price, req.UserID, req.StartDate, req.EndDate, req.ExternalID, id,
+	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.updateMissError(ctx, id, req.UserID)
+	}
+	if isExternalIDConflict(err) {
+		r.log.Warn("Subscription external ID already exists", map[string]any{"id": id, "external_id": *req.ExternalID})
+		return nil, ErrExternalIDExists
+	}
 	if err != nil {
 		r.log.Error("Failed to update subscription", map[string]any{"error": err, "id": id})
 		return nil, fmt.Errorf("failed to update subscription: %w", err)
 	}
 
 	r.log.Info("Subscription updated", map[string]any{"id": id})
-	return &sub, nil
+	return sub, nil
 }
 
-func (r *repository) Delete(ctx context.Context, id int) error {
-	result, err := r.db.Exec(ctx, "DELETE FROM subscriptions WHERE id=$1", id)
+// updateMissError explains an Update matching no row: the subscription does
+// not exist, or belongs to another user than userID. It reads the primary the
+// update ran on.
+func (r *repository) updateMissError(ctx context.Context, id int, userID uuid.UUID) error {
+	var exists bool
+	err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM subscriptions WHERE id = $1)", id).Scan(&exists)
 	if err != nil {
-		r.log.Error("Failed to delete subscription", map[string]any{"error": err, "id": id})
-		return fmt.Errorf("failed to delete subscription: %w", err)
+		r.log.Error("Failed to check subscription", map[string]any{"error": err, "id": id})
+		return fmt.Errorf("failed to check subscription: %w", err)
 	}
-	if result.RowsAffected() == 0 {
+
+	if !exists {
+		r.log.Warn("Subscription not found for update", map[string]any{"id": id})
+		return ErrNotFound
+	}
+	r.log.Warn("Attempt to change subscription owner", map[string]any{"id": id, "requested_user_id": userID})
+	return ErrUserIDImmutable
+}
+
+// Delete removes a subscription and returns the deleted row.
+func (r *repository) Delete(ctx context.Context, id int) (*Subscription, error) {
+	sub, err := querySubscription(ctx, r.db,
+		"DELETE FROM subscriptions WHERE id=$1 RETURNING "+subscriptionColumns,
+		id,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
 		r.log.Warn("Subscription not found for deletion", map[string]any{"id": id})
-		return fmt.Errorf("subscription not found")
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to delete subscription", map[string]any{"error": err, "id": id})
+		return nil, fmt.Errorf("failed to delete subscription: %w", err)
 	}
 
 	r.log.Info("Subscription deleted", map[string]any{"id": id})
-	return nil
+	return sub, nil
+}
+
+func (r *repository) GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
+	where, args := costFilter(startDate, endDate, userID, serviceName)
+	return r.queryCost(ctx, where, args)
+}
+
+// GetCostByQuery is GetCostByPeriod for any of the users and services of query.
+func (r *repository) GetCostByQuery(ctx context.Context, query CostQuery) (int64, int, error) {
+	where, args := costQueryFilter(query)
+	return r.queryCost(ctx, where, args)
+}
+
+// queryCost returns the total cost and count of the subscriptions matching the
+// cost filter predicates where.
+func (r *repository) queryCost(ctx context.Context, where string, args []any) (int64, int, error) {
+	query := "SELECT COALESCE(SUM(price), 0) as total_cost, COUNT(*) as count FROM subscriptions WHERE 1=1" + where
+
+	var totalCost int64
+	var count int
+	err := r.reader().QueryRow(ctx, query, args...).Scan(&totalCost, &count)
+	if err != nil {
+		r.log.Error("Failed to calculate cost", map[string]any{"error": err})
+		return 0, 0, fmt.Errorf("failed to calculate cost: %w", err)
+	}
+
+	r.log.Info("Cost calculated", map[string]any{"total": totalCost, "count": count})
+	return totalCost, count, nil
+}
+
+// GetCostWithIDs is GetCostByPeriod also returning the IDs of the
+// subscriptions counted, in ascending order.
+func (r *repository) GetCostWithIDs(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error) {
+	where, args := costFilter(startDate, endDate, userID, serviceName)
+	return r.queryCostWithIDs(ctx, where, args)
+}
+
+// GetCostByQueryWithIDs is GetCostByQuery also returning the IDs of the
+// subscriptions counted, in ascending order.
+func (r *repository) GetCostByQueryWithIDs(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error) {
+	where, args := costQueryFilter(query)
+	return r.queryCostWithIDs(ctx, where, args)
+}
+
+// queryCostWithIDs is queryCost also returning the IDs of the subscriptions
+// counted. A single statement reads the total, count and IDs from one
+// snapshot, so they always agree.
+func (r *repository) queryCostWithIDs(ctx context.Context, where string, args []any) (*CostWithIDsResponse, error) {
+	query := "SELECT COALESCE(SUM(price), 0), COUNT(*), COALESCE(array_agg(id ORDER BY id), '{}') FROM subscriptions WHERE 1=1" + where
+
+	cost := &CostWithIDsResponse{}
+	err := r.reader().QueryRow(ctx, query, args...).Scan(&cost.TotalCost, &cost.Count, &cost.SubscriptionIDs)
+	if err != nil {
+		r.log.Error("Failed to calculate cost with subscription IDs", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to calculate cost with subscription IDs: %w", err)
+	}
+
+	r.log.Info("Cost calculated", map[string]any{"total": cost.TotalCost, "count": cost.Count})
+	return cost, nil
+}
+
+// GetCostVersion returns the count and latest updated_at of subscriptions
+// matching the cost filter. LastModified is nil when nothing matches.
+func (r *repository) GetCostVersion(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error) {
+	where, args := costFilter(startDate, endDate, userID, serviceName)
+	query := "SELECT COUNT(*), MAX(updated_at) FROM subscriptions WHERE 1=1" + where
+
+	version := &CostVersion{StartDate: startDate, EndDate: endDate}
+	if err := r.reader().QueryRow(ctx, query, args...).Scan(&version.Count, &version.LastModified); err != nil {
+		r.log.Error("Failed to query cost version", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to query cost version: %w", err)
+	}
+
+	return version, nil
+}
+
+// Export returns all subscriptions matching the filter, using the same period
+// semantics as the cost calculation.
+func (r *repository) Export(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error) {
+	where, args := costFilter(filter.StartDate, filter.EndDate, filter.UserID, filter.ServiceName)
+	query := "SELECT " + subscriptionColumns + " FROM subscriptions WHERE 1=1" + where + " ORDER BY created_at DESC, id DESC"
+
+	rows, err := r.reader().Query(ctx, query, args...)
+	if err != nil {
+		r.log.Error("Failed to query subscriptions for export", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+
+	subscriptions, err := collectSubscriptions(rows)
+	if err != nil {
+		r.log.Error("Failed to scan subscription", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to scan subscription: %w", err)
+	}
+
+	r.log.Info("Exported subscriptions", map[string]any{"count": len(subscriptions)})
+	return subscriptions, nil
+}
+
+// GetChangedSince returns the subscriptions updated after since, oldest change
+// first.
+func (r *repository) GetChangedSince(ctx context.Context, since time.Time) ([]Subscription, error) {
+	rows, err := r.reader().Query(ctx,
+		"SELECT "+subscriptionColumns+" FROM subscriptions WHERE updated_at > $1 ORDER BY updated_at, id",
+		since,
+	)
+	if err != nil {
+		r.log.Error("Failed to query changed subscriptions", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+
+	subscriptions, err := collectSubscriptions(rows)
+	if err != nil {
+		r.log.Error("Failed to scan subscription", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to scan subscription: %w", err)
+	}
+
+	r.log.Info("Retrieved changed subscriptions", map[string]any{"count": len(subscriptions), "since": since})
+	return subscriptions, nil
+}
+
+// isRetryable reports whether err is a transient conflict with a concurrent
+// transaction or a clash of generated reference codes, after which the
+// statement can be run again.
+func isRetryable(err error) bool {
+	return hasCode(err, serializationFailure) || hasCode(err, deadlockDetected) || isUniqueViolationOf(err, referenceCodeIndex)
+}
+
+// hasCode reports whether err is a PostgreSQL error with the given code.
+func hasCode(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+// isExternalIDConflict reports whether err is a unique violation of the
+// external ID index.
+func isExternalIDConflict(err error) bool {
+	return isUniqueViolationOf(err, externalIDIndex)
+}
+
+// isUniqueViolationOf reports whether err is a unique violation of index.
+func isUniqueViolationOf(err error, index string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == index
+}
+
+// querySubscription runs a query returning subscriptionColumns and maps its
+// only row. It returns pgx.ErrNoRows when the query returns no row.
+func querySubscription(ctx context.Context, db DB, sql string, args ...any) (*Subscription, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Subscription])
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// collectSubscriptions maps rows returning subscriptionColumns and closes them.
+// The result is never nil, so an empty listing encodes as [].
+func collectSubscriptions(rows pgx.Rows) ([]Subscription, error) {
+	subscriptions, err := pgx.CollectRows(rows, pgx.RowToStructByName[Subscription])
+	if err != nil {
+		return nil, err
+	}
+	if subscriptions == nil {
+		subscriptions = make([]Subscription, 0)
+	}
+	return subscriptions, nil
+}
+
+// sortColumns maps the accepted sort_by values to SQL expressions. ORDER BY is
+// only ever built from these, never from request input.
+var sortColumns = map[string]string{
+	"id":           "id",
+	"service_name": "service_name",
+	"price":        "price",
+	"start_date":   "month_date(start_date)",
+	"end_date":     "month_date(end_date)",
+	"created_at":   "created_at",
+	"updated_at":   "updated_at",
+}
+
+// orderBy returns the ORDER BY clause for sort, defaulting to the newest first.
+// Unknown fields fall back to the default. id breaks ties between rows sharing
+// a value, e.g. rows created in one transaction with the same created_at.
+func orderBy(sort Sort) string {
+	column, ok := sortColumns[sort.Field]
+	if !ok {
+		return "ORDER BY created_at DESC, id DESC"
+	}
+
+	direction := "ASC"
+	if sort.Order == "desc" {
+		direction = "DESC"
+	}
+	return "ORDER BY " + column + " " + direction + ", id " + direction
 }
 
-func (r *repository) GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int, int, error) {
-	query := "SELECT COALESCE(SUM(price), 0) as total_cost, COUNT(*) as count FROM subscriptions WHERE 1=1"
+// costFilter builds the WHERE predicates shared by the cost queries. Dates are
+// compared as months through month_date, which the query indexes cover: the
+// MM-YYYY text does not sort chronologically.
+func costFilter(startDate, endDate string, userID *uuid.UUID, serviceName *string) (string, []any) {
+	query := ""
 	args := []any{}
 	argCount := 1
 
 	if startDate != "" {
-		query += fmt.Sprintf(" AND start_date >= $%d", argCount)
+		query += fmt.Sprintf(" AND month_date(start_date) >= month_date($%d)", argCount)
 		args = append(args, startDate)
 		argCount++
 	}
 
 	if endDate != "" {
-		query += fmt.Sprintf(" AND (end_date IS NULL OR end_date >= $%d)", argCount)
+		query += fmt.Sprintf(" AND (end_date IS NULL OR month_date(end_date) >= month_date($%d))", argCount)
 		args = append(args, endDate)
 		argCount++
 	}
@@ -135,13 +563,434 @@ func (r *repository) GetCostByPeriod(ctx context.Context, startDate, endDate str
 		args = append(args, *serviceName)
 	}
 
-	var totalCost, count int
-	err := r.db.QueryRow(ctx, query, args...).Scan(&totalCost, &count)
+	return query, args
+}
+
+// costQueryFilter builds the WHERE predicates of a cost query: the period of
+// costFilter and the lists of users and services.
+func costQueryFilter(q CostQuery) (string, []any) {
+	query, args := costFilter(q.StartDate, q.EndDate, nil, nil)
+
+	if len(q.UserIDs) > 0 {
+		args = append(args, q.UserIDs)
+		query += fmt.Sprintf(" AND user_id = ANY($%d)", len(args))
+	}
+
+	if len(q.ServiceNames) > 0 {
+		args = append(args, q.ServiceNames)
+		query += fmt.Sprintf(" AND service_name = ANY($%d)", len(args))
+	}
+
+	return query, args
+}
+
+// GetServices returns a page of distinct service names matching filter and the
+// number of all of them.
+func (r *repository) GetServices(ctx context.Context, filter ServiceFilter, limit, offset int) ([]string, int, error) {
+	where, args := serviceFilter(filter)
+
+	var total int
+	err := r.reader().QueryRow(ctx, "SELECT COUNT(DISTINCT service_name) FROM subscriptions WHERE 1=1"+where, args...).Scan(&total)
 	if err != nil {
-		r.log.Error("Failed to calculate cost", map[string]any{"error": err})
-		return 0, 0, fmt.Errorf("failed to calculate cost: %w", err)
+		r.log.Error("Failed to count services", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to count services: %w", err)
 	}
 
-	r.log.Info("Cost calculated", map[string]any{"total": totalCost, "count": count})
-	return totalCost, count, nil
+	orderBy := "service_name"
+	if filter.Query != "" {
+		// The query is the last argument of the filter.
+		q := len(args)
+		orderBy = fmt.Sprintf(`CASE WHEN service_name ILIKE $%d ESCAPE '\' THEN 0 WHEN service_name ILIKE $%d || '%%' ESCAPE '\' THEN 1 ELSE 2 END, service_name`, q, q)
+	}
+
+	rows, err := r.reader().Query(ctx,
+		fmt.Sprintf("SELECT service_name FROM subscriptions WHERE 1=1%s GROUP BY service_name ORDER BY %s LIMIT $%d OFFSET $%d", where, orderBy, len(args)+1, len(args)+2),
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		r.log.Error("Failed to query services", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to query services: %w", err)
+	}
+	defer rows.Close()
+
+	services := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			r.log.Error("Failed to scan service name", map[string]any{"error": err})
+			return nil, 0, fmt.Errorf("failed to scan service name: %w", err)
+		}
+		services = append(services, name)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("Failed to read services", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to read services: %w", err)
+	}
+
+	r.log.Info("Retrieved services", map[string]any{"count": len(services), "total": total, "prefix": filter.Prefix, "query": filter.Query})
+	return services, total, nil
+}
+
+// serviceFilter builds the WHERE predicates of the service name queries. The
+// prefix and query are matched literally, their LIKE wildcards escaped.
+func serviceFilter(filter ServiceFilter) (string, []any) {
+	query := ""
+	args := []any{}
+	argCount := 1
+
+	if filter.Prefix != "" {
+		query += fmt.Sprintf(` AND service_name ILIKE $%d || '%%' ESCAPE '\'`, argCount)
+		args = append(args, escapeLike(filter.Prefix))
+		argCount++
+	}
+
+	if filter.Query != "" {
+		query += fmt.Sprintf(` AND service_name ILIKE '%%' || $%d || '%%' ESCAPE '\'`, argCount)
+		args = append(args, escapeLike(filter.Query))
+	}
+
+	return query, args
+}
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike returns s matching itself literally in a LIKE pattern with
+// ESCAPE '\'.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// activeInCurrentMonth matches the subscriptions active in the current month.
+const activeInCurrentMonth = `month_date(start_date) <= date_trunc('month', CURRENT_DATE)
+			AND (end_date IS NULL OR month_date(end_date) >= date_trunc('month', CURRENT_DATE))`
+
+// activeSubscriberFilter matches the subscriptions to the service $1 that are
+// active in the current month.
+const activeSubscriberFilter = `WHERE service_name = $1
+			AND ` + activeInCurrentMonth
+
+// GetSubscribers returns a page of distinct users with a subscription to the
+// service that is active in the current month, and the number of all of them.
+func (r *repository) GetSubscribers(ctx context.Context, serviceName string, limit, offset int) ([]uuid.UUID, int, error) {
+	var total int
+	err := r.reader().QueryRow(ctx,
+		"SELECT COUNT(DISTINCT user_id) FROM subscriptions "+activeSubscriberFilter,
+		serviceName,
+	).Scan(&total)
+	if err != nil {
+		r.log.Error("Failed to count subscribers", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to count subscribers: %w", err)
+	}
+
+	rows, err := r.reader().Query(ctx,
+		"SELECT DISTINCT user_id FROM subscriptions "+activeSubscriberFilter+" ORDER BY user_id LIMIT $2 OFFSET $3",
+		serviceName, limit, offset,
+	)
+	if err != nil {
+		r.log.Error("Failed to query subscribers", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to query subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	userIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			r.log.Error("Failed to scan subscriber", map[string]any{"error": err})
+			return nil, 0, fmt.Errorf("failed to scan subscriber: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("Failed to read subscribers", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to read subscribers: %w", err)
+	}
+
+	r.log.Info("Retrieved subscribers", map[string]any{"count": len(userIDs), "total": total, "service_name": serviceName})
+	return userIDs, total, nil
+}
+
+// GetUsers returns a page of distinct users with the number of their
+// subscriptions, ordered by user ID, and the number of all users.
+func (r *repository) GetUsers(ctx context.Context, limit, offset int) ([]UserSubscriptions, int, error) {
+	var total int
+	if err := r.reader().QueryRow(ctx, "SELECT COUNT(DISTINCT user_id) FROM subscriptions").Scan(&total); err != nil {
+		r.log.Error("Failed to count users", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	rows, err := r.reader().Query(ctx,
+		"SELECT user_id, COUNT(*) FROM subscriptions GROUP BY user_id ORDER BY user_id LIMIT $1 OFFSET $2",
+		limit, offset,
+	)
+	if err != nil {
+		r.log.Error("Failed to query users", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]UserSubscriptions, 0)
+	for rows.Next() {
+		var user UserSubscriptions
+		if err := rows.Scan(&user.UserID, &user.Subscriptions); err != nil {
+			r.log.Error("Failed to scan user", map[string]any{"error": err})
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("Failed to read users", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to read users: %w", err)
+	}
+
+	r.log.Info("Retrieved users", map[string]any{"count": len(users), "total": total})
+	return users, total, nil
+}
+
+// GetServiceDiversity returns a page of distinct users with the number of
+// distinct services they subscribe to, ordered by user ID, and the number of
+// all users.
+func (r *repository) GetServiceDiversity(ctx context.Context, limit, offset int) ([]UserServices, int, error) {
+	var total int
+	if err := r.reader().QueryRow(ctx, "SELECT COUNT(DISTINCT user_id) FROM subscriptions").Scan(&total); err != nil {
+		r.log.Error("Failed to count users", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	rows, err := r.reader().Query(ctx,
+		"SELECT user_id, COUNT(DISTINCT service_name) FROM subscriptions GROUP BY user_id ORDER BY user_id LIMIT $1 OFFSET $2",
+		limit, offset,
+	)
+	if err != nil {
+		r.log.Error("Failed to query service diversity", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to query service diversity: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]UserServices, 0)
+	for rows.Next() {
+		var user UserServices
+		if err := rows.Scan(&user.UserID, &user.DistinctServices); err != nil {
+			r.log.Error("Failed to scan service diversity", map[string]any{"error": err})
+			return nil, 0, fmt.Errorf("failed to scan service diversity: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("Failed to read service diversity", map[string]any{"error": err})
+		return nil, 0, fmt.Errorf("failed to read service diversity: %w", err)
+	}
+
+	r.log.Info("Retrieved service diversity", map[string]any{"count": len(users), "total": total})
+	return users, total, nil
+}
+
+// GetStats computes the subscription aggregates. ComputedAt is left unset.
+func (r *repository) GetStats(ctx context.Context) (*StatsResponse, error) {
+	var stats StatsResponse
+	err := r.reader().QueryRow(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE active), COUNT(DISTINCT user_id), COUNT(DISTINCT service_name), COALESCE(SUM(price) FILTER (WHERE active), 0)
+		FROM (
+			SELECT user_id, service_name, price,
+				month_date(start_date) <= date_trunc('month', CURRENT_DATE)
+					AND (end_date IS NULL OR month_date(end_date) >= date_trunc('month', CURRENT_DATE)) AS active
+			FROM subscriptions
+		) s`,
+	).Scan(&stats.Subscriptions, &stats.ActiveSubscriptions, &stats.Users, &stats.Services, &stats.MonthlyCost)
+	if err != nil {
+		r.log.Error("Failed to query stats", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to query stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+func (r *repository) GetDateRange(ctx context.Context, userID *uuid.UUID) (*DateRangeResponse, error) {
+	query := "SELECT to_char(MIN(month_date(start_date)), 'MM-YYYY'), to_char(MAX(month_date(end_date)), 'MM-YYYY') FROM subscriptions"
+	args := []any{}
+
+	if userID != nil {
+		query += " WHERE user_id = $1"
+		args = append(args, userID)
+	}
+
+	var dateRange DateRangeResponse
+	err := r.reader().QueryRow(ctx, query, args...).Scan(&dateRange.MinStartDate, &dateRange.MaxEndDate)
+	if err != nil {
+		r.log.Error("Failed to query date range", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to query date range: %w", err)
+	}
+
+	return &dateRange, nil
+}
+
+func (r *repository) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", map[string]any{"error": err, "user_id": userID})
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	result, err := tx.Exec(ctx, "DELETE FROM subscriptions WHERE user_id=$1", userID)
+	if err != nil {
+		r.log.Error("Failed to delete user subscriptions", map[string]any{"error": err, "user_id": userID})
+		return 0, fmt.Errorf("failed to delete user subscriptions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.log.Error("Failed to commit transaction", map[string]any{"error": err, "user_id": userID})
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.log.Info("User subscriptions deleted", map[string]any{"user_id": userID, "count": result.RowsAffected()})
+	return result.RowsAffected(), nil
+}
+
+// CancelByUser ends the subscriptions of the user active in the current month
+// with the current month. Subscriptions already ending by then or starting
+// later are left untouched.
+func (r *repository) CancelByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", map[string]any{"error": err, "user_id": userID})
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	result, err := tx.Exec(ctx,
+		`UPDATE subscriptions SET end_date=to_char(CURRENT_DATE, 'MM-YYYY'), updated_at=CURRENT_TIMESTAMP
+		WHERE user_id=$1
+			AND month_date(start_date) <= date_trunc('month', CURRENT_DATE)
+			AND (end_date IS NULL OR month_date(end_date) > date_trunc('month', CURRENT_DATE))`,
+		userID,
+	)
+	if err != nil {
+		r.log.Error("Failed to cancel user subscriptions", map[string]any{"error": err, "user_id": userID})
+		return 0, fmt.Errorf("failed to cancel user subscriptions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.log.Error("Failed to commit transaction", map[string]any{"error": err, "user_id": userID})
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.log.Info("User subscriptions cancelled", map[string]any{"user_id": userID, "count": result.RowsAffected()})
+	return result.RowsAffected(), nil
+}
+
+// Renew creates a new subscription copying service, price and user of the original
+// one for the new period and links it to the original through renewed_from_id.
+func (r *repository) Renew(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error) {
+	sub, err := querySubscription(ctx, r.db,
+		"INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date, renewed_from_id) SELECT service_name, price, user_id, $2, $3, id FROM subscriptions WHERE id=$1 RETURNING "+subscriptionColumns,
+		id, req.StartDate, req.EndDate,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.log.Warn("Subscription not found for renewal", map[string]any{"id": id})
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to renew subscription", map[string]any{"error": err, "id": id})
+		return nil, fmt.Errorf("failed to renew subscription: %w", err)
+	}
+
+	r.log.Info("Subscription renewed", map[string]any{"id": sub.ID, "renewed_from_id": id})
+	return sub, nil
+}
+
+// RecomputeSummaries replaces the user_cost_summary rows with the cost and
+// number of each user's subscriptions active in the current month, and returns
+// the number of users summarized.
+func (r *repository) RecomputeSummaries(ctx context.Context) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", map[string]any{"error": err})
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, "DELETE FROM user_cost_summary"); err != nil {
+		r.log.Error("Failed to clear summaries", map[string]any{"error": err})
+		return 0, fmt.Errorf("failed to clear summaries: %w", err)
+	}
+
+	result, err := tx.Exec(ctx,
+		`INSERT INTO user_cost_summary (user_id, monthly_cost, active_subscriptions, computed_at)
+		SELECT user_id, SUM(price), COUNT(*), NOW() FROM subscriptions
+		WHERE `+activeInCurrentMonth+`
+		GROUP BY user_id`,
+	)
+	if err != nil {
+		r.log.Error("Failed to compute summaries", map[string]any{"error": err})
+		return 0, fmt.Errorf("failed to compute summaries: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.log.Error("Failed to commit transaction", map[string]any{"error": err})
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.log.Info("Summaries recomputed", map[string]any{"users": result.RowsAffected()})
+	return result.RowsAffected(), nil
+}
+
+func (r *repository) GetUserSummary(ctx context.Context, userID uuid.UUID) (*UserCostSummary, error) {
+	summary := UserCostSummary{UserID: userID}
+	err := r.reader().QueryRow(ctx,
+		"SELECT monthly_cost, active_subscriptions, computed_at FROM user_cost_summary WHERE user_id=$1",
+		userID,
+	).Scan(&summary.MonthlyCost, &summary.ActiveSubscriptions, &summary.ComputedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSummaryNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to query summary", map[string]any{"error": err, "user_id": userID})
+		return nil, fmt.Errorf("failed to query summary: %w", err)
+	}
+
+	return &summary, nil
+}
+
+// GetBudgetCosts returns every budget with the monthly cost of its service,
+// ordered by service name. OverBudget and Overage are left unset.
+func (r *repository) GetBudgetCosts(ctx context.Context) ([]BudgetStatus, error) {
+	rows, err := r.reader().Query(ctx,
+		`SELECT b.service_name, b.monthly_cap, COALESCE(SUM(s.price), 0)
+		FROM budgets b
+		LEFT JOIN subscriptions s ON s.service_name = b.service_name
+			AND `+activeInCurrentMonth+`
+		GROUP BY b.service_name, b.monthly_cap
+		ORDER BY b.service_name`,
+	)
+	if err != nil {
+		r.log.Error("Failed to query budgets", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	budgets := make([]BudgetStatus, 0)
+	for rows.Next() {
+		var budget BudgetStatus
+		if err := rows.Scan(&budget.ServiceName, &budget.MonthlyCap, &budget.MonthlyCost); err != nil {
+			r.log.Error("Failed to scan budget", map[string]any{"error": err})
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, budget)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("Failed to read budgets", map[string]any{"error": err})
+		return nil, fmt.Errorf("failed to read budgets: %w", err)
+	}
+
+	return budgets, nil
 }
@@ -0,0 +1,115 @@
+package subscriptions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// benchmarkSubscriptions is the size of the dataset seeded for benchmarks.
+const benchmarkSubscriptions = 100_000
+
+// seedBenchmarkDB fills the subscriptions table with benchmarkSubscriptions
+// rows over 2000 users and 50 services, a third of them open-ended, and
+// returns one of the seeded users.
+func seedBenchmarkDB(b *testing.B) (*pgxpool.Pool, uuid.UUID) {
+	b.Helper()
+
+	db := setupTestDB(b)
+
+	ctx := context.Background()
+	_, err := db.Exec(ctx,
+		`INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
+		SELECT 'Service ' || (i % 50),
+			100 + i % 900,
+			md5((i % 2000)::text)::uuid,
+			lpad((1 + i % 12)::text, 2, '0') || '-' || (2015 + i % 11),
+			CASE WHEN i % 3 = 0 THEN NULL ELSE lpad((1 + i % 12)::text, 2, '0') || '-' || (2025 + i % 4) END
+		FROM generate_series(1, $1) AS i`,
+		benchmarkSubscriptions,
+	)
+	if err != nil {
+		b.Fatalf("failed to seed subscriptions: %v", err)
+	}
+	if _, err := db.Exec(ctx, "ANALYZE subscriptions"); err != nil {
+		b.Fatalf("failed to analyze subscriptions: %v", err)
+	}
+
+	var userID uuid.UUID
+	if err := db.QueryRow(ctx, "SELECT md5('7')::uuid").Scan(&userID); err != nil {
+		b.Fatalf("failed to pick a user: %v", err)
+	}
+
+	b.Cleanup(func() {
+		_, _ = db.Exec(context.Background(), "DELETE FROM subscriptions")
+		db.Close()
+	})
+	b.ResetTimer()
+
+	return db, userID
+}
+
+func BenchmarkRepository_GetAll(b *testing.B) {
+	db, _ := seedBenchmarkDB(b)
+	repo := NewRepository(db, &MockLogger{})
+
+	for _, sort := range []Sort{{}, {Field: "start_date", Order: "desc"}} {
+		b.Run("sort="+sort.Field, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := repo.GetAll(context.Background(), sort, 100); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRepository_GetCostByPeriod(b *testing.B) {
+	db, userID := seedBenchmarkDB(b)
+	repo := NewRepository(db, &MockLogger{})
+	serviceName := "Service 7"
+
+	tests := []struct {
+		name        string
+		userID      *uuid.UUID
+		serviceName *string
+	}{
+		{name: "period"},
+		{name: "user", userID: &userID},
+		{name: "service", serviceName: &serviceName},
+	}
+
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, _, err := repo.GetCostByPeriod(context.Background(), "01-2020", "12-2025", tt.userID, tt.serviceName); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRepository_Export(b *testing.B) {
+	db, userID := seedBenchmarkDB(b)
+	repo := NewRepository(db, &MockLogger{})
+
+	for b.Loop() {
+		if _, err := repo.Export(context.Background(), SubscriptionFilter{StartDate: "01-2020", UserID: &userID}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRepository_GetSubscribers(b *testing.B) {
+	db, _ := seedBenchmarkDB(b)
+	repo := NewRepository(db, &MockLogger{})
+
+	for b.Loop() {
+		if _, _, err := repo.GetSubscribers(context.Background(), "Service 7", 20, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
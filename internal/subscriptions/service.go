@@ -2,120 +2,976 @@ package subscriptions
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/n-korel/user-subscriptions-api/internal/logger"
+	"golang.org/x/sync/singleflight"
 )
 
 type SubscriptionService interface {
-	GetAllSubscriptions(ctx context.Context) ([]Subscription, error)
+	GetAllSubscriptions(ctx context.Context, sort Sort) ([]Subscription, error)
+	ListSubscriptions(ctx context.Context, sort Sort) (*SubscriptionList, error)
 	GetSubscriptionByID(ctx context.Context, id int) (*Subscription, error)
 	CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error)
 	UpdateSubscription(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error)
-	DeleteSubscription(ctx context.Context, id int) error
+	DeleteSubscription(ctx context.Context, id int) (*Subscription, error)
 	GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error)
+	GetCostVersion(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error)
+	GetCostWithIDs(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error)
+	GetCostByQuery(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error)
+	PreviewCostChange(ctx context.Context, req CostPreviewRequest) (*CostPreviewResponse, error)
+	GetServices(ctx context.Context, filter ServiceFilter, limit, offset int) (*Page[string], error)
+	GetDateRange(ctx context.Context, userID *uuid.UUID) (*DateRangeResponse, error)
+	DeleteUserSubscriptions(ctx context.Context, userID uuid.UUID) (int64, error)
+	CancelUserSubscriptions(ctx context.Context, userID uuid.UUID) (int64, error)
+	RenewSubscription(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error)
+	ExportSubscriptions(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error)
+	GetSubscribers(ctx context.Context, serviceName string, limit, offset int) (*Page[uuid.UUID], error)
+	GetRollingCost(ctx context.Context, months int, userID *uuid.UUID) (*CostResponse, error)
+	ValidateSubscription(ctx context.Context, req CreateSubscriptionRequest) []*ValidationError
+	GetStats(ctx context.Context, fresh bool) (*StatsResponse, error)
+	GetUsers(ctx context.Context, limit, offset int) (*Page[UserSubscriptions], error)
+	GetServiceDiversity(ctx context.Context, limit, offset int) (*Page[UserServices], error)
+	GetBudgetStatus(ctx context.Context) ([]BudgetStatus, error)
+	GetSubscriptionByExternalID(ctx context.Context, externalID string) (*Subscription, error)
+	GetChangesSince(ctx context.Context, since string) ([]Subscription, error)
+	GetMeta(ctx context.Context) *MetaResponse
+	RecomputeSummaries(ctx context.Context) (*RecomputeSummariesResponse, error)
+	GetUserSummary(ctx context.Context, userID uuid.UUID) (*UserCostSummary, error)
 }
 
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+
+	// maxRollingMonths caps the window of the rolling cost.
+	maxRollingMonths = 120
+)
+
+// ValidationError reports an invalid value of a request field.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// newValidationError builds a ValidationError and counts it in the validation failures metric.
+func newValidationError(field, message string) error {
+	return countValidationError(&ValidationError{Field: field, Message: message})
+}
+
+// countValidationError counts err in the validation failures metric and returns it.
+func countValidationError(err *ValidationError) error {
+	validationFailures.WithLabelValues(err.Field).Inc()
+	return err
+}
+
+// ErrPastStartDate is returned when a create in strict mode starts before the
+// current month, see WithRejectPastStart.
+var ErrPastStartDate = errors.New("start_date must not be before the current month")
+
+// defaultServiceNamePattern allows letters, digits, spaces and common
+// punctuation, rejecting control characters, symbols and emoji.
+var defaultServiceNamePattern = regexp.MustCompile(`^[\p{L}\p{M}\p{N} .,:;!?&+'"()/_#@-]+$`)
+
+// monthLayout is the time layout of the MM-YYYY dates used across the API.
+const monthLayout = "01-2006"
+
+// nowDate stands for the current month in the dates of cost queries.
+const nowDate = "now"
+
 type service struct {
 	repo SubscriptionRepository
 	log  logger.LoggerInterface
+
+	defaultDurationMonths int
+	maxCostMonths         int
+	listHardCap           int
+	serviceNamePattern    *regexp.Regexp
+	rejectPastStart       bool
+	now                   func() time.Time
+
+	// costGroup deduplicates concurrent identical cost queries; nil disables it.
+	costGroup *singleflight.Group
+
+	// cacheStats keeps the last computed stats in statsSnapshot.
+	cacheStats    bool
+	statsMu       sync.RWMutex
+	statsSnapshot *StatsResponse
+
+	// overBudget holds the services over budget at the last budget check, so
+	// that budget.exceeded is emitted once per crossing of the cap.
+	budgetMu   sync.Mutex
+	overBudget map[string]bool
+}
+
+type ServiceOption func(*service)
+
+// WithDefaultDurationMonths makes subscriptions created without end_date run
+// for the given number of months: end_date is set to start_date plus months.
+// Zero keeps such subscriptions open-ended.
+func WithDefaultDurationMonths(months int) ServiceOption {
+	return func(s *service) {
+		s.defaultDurationMonths = months
+	}
+}
+
+// WithMaxCostMonths rejects cost queries whose period spans more than months
+// months, counting both the start and the end month. Zero allows any period.
+func WithMaxCostMonths(months int) ServiceOption {
+	return func(s *service) {
+		s.maxCostMonths = months
+	}
+}
+
+// WithListHardCap makes ListSubscriptions return at most limit subscriptions,
+// flagging the listing as truncated when more exist. Zero lists all of them.
+func WithListHardCap(limit int) ServiceOption {
+	return func(s *service) {
+		s.listHardCap = limit
+	}
+}
+
+// WithServiceNamePattern restricts service names to those matching pattern
+// instead of defaultServiceNamePattern. Anchor the pattern to constrain the
+// whole name.
+func WithServiceNamePattern(pattern *regexp.Regexp) ServiceOption {
+	return func(s *service) {
+		s.serviceNamePattern = pattern
+	}
+}
+
+// WithRejectPastStart makes CreateSubscription reject subscriptions starting
+// before the current month with ErrPastStartDate. Backfills that write through
+// the repository directly are not affected.
+func WithRejectPastStart() ServiceOption {
+	return func(s *service) {
+		s.rejectPastStart = true
+	}
+}
+
+// WithCostDeduplication makes concurrent cost queries with identical parameters
+// share a single repository call.
+func WithCostDeduplication() ServiceOption {
+	return func(s *service) {
+		s.costGroup = &singleflight.Group{}
+	}
+}
+
+// WithStatsCache makes GetStats serve the last computed snapshot unless fresh
+// stats are requested. The snapshot is kept up to date by a StatsRefresher.
+func WithStatsCache() ServiceOption {
+	return func(s *service) {
+		s.cacheStats = true
+	}
+}
+
+func NewService(repo SubscriptionRepository, log logger.LoggerInterface, opts ...ServiceOption) SubscriptionService {
+	s := &service{repo: repo, log: log, serviceNamePattern: defaultServiceNamePattern, now: time.Now, overBudget: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetAllSubscriptions returns every subscription, whatever the list hard cap.
+func (s *service) GetAllSubscriptions(ctx context.Context, sort Sort) (_ []Subscription, err error) {
+	defer s.logDuration("GetAllSubscriptions", time.Now(), &err)
+
+	if err := validateSort(sort); err != nil {
+		return nil, err
+	}
+
+	return s.withComputedList(s.repo.GetAll(ctx, sort, 0))
 }
 
-func NewService(repo SubscriptionRepository, log logger.LoggerInterface) SubscriptionService {
-	return &service{repo: repo, log: log}
+// ListSubscriptions returns the subscriptions for the listing endpoint, cut
+// to the list hard cap.
+func (s *service) ListSubscriptions(ctx context.Context, sort Sort) (_ *SubscriptionList, err error) {
+	defer s.logDuration("ListSubscriptions", time.Now(), &err)
+
+	if err := validateSort(sort); err != nil {
+		return nil, err
+	}
+
+	if s.listHardCap == 0 {
+		subs, err := s.withComputedList(s.repo.GetAll(ctx, sort, 0))
+		if err != nil {
+			return nil, err
+		}
+		return &SubscriptionList{Subscriptions: subs}, nil
+	}
+
+	// One extra row tells whether anything was left out.
+	subs, err := s.withComputedList(s.repo.GetAll(ctx, sort, s.listHardCap+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(subs) <= s.listHardCap {
+		return &SubscriptionList{Subscriptions: subs}, nil
+	}
+
+	s.log.Warn("Subscription listing truncated", map[string]any{"limit": s.listHardCap})
+	return &SubscriptionList{Subscriptions: subs[:s.listHardCap], Truncated: true}, nil
 }
 
-func (s *service) GetAllSubscriptions(ctx context.Context) ([]Subscription, error) {
-	return s.repo.GetAll(ctx)
+func validateSort(sort Sort) error {
+	if sort.Field != "" {
+		if _, ok := sortColumns[sort.Field]; !ok {
+			return newValidationError("sort_by", fmt.Sprintf("sort_by %q is not supported", sort.Field))
+		}
+	}
+
+	if sort.Order != "" && sort.Order != "asc" && sort.Order != "desc" {
+		return newValidationError("order", "order must be asc or desc")
+	}
+
+	return nil
+}
+
+func (s *service) GetSubscriptionByID(ctx context.Context, id int) (_ *Subscription, err error) {
+	defer s.logDuration("GetSubscriptionByID", time.Now(), &err)
+
+	return s.withComputed(s.repo.GetByID(ctx, id))
 }
 
-func (s *service) GetSubscriptionByID(ctx context.Context, id int) (*Subscription, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *service) GetSubscriptionByExternalID(ctx context.Context, externalID string) (_ *Subscription, err error) {
+	defer s.logDuration("GetSubscriptionByExternalID", time.Now(), &err)
+
+	return s.withComputed(s.repo.GetByExternalID(ctx, externalID))
 }
 
-func (s *service) CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+func (s *service) CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (_ *Subscription, err error) {
+	defer s.logDuration("CreateSubscription", time.Now(), &err)
+
 	if err := s.validateSubscriptionRequest(req); err != nil {
 		s.log.Warn("Validation failed", map[string]any{"error": err.Error()})
 		return nil, err
 	}
 
-	return s.repo.Create(ctx, req)
+	if s.startsInPast(req.StartDate) {
+		s.log.Warn("Past start date rejected", map[string]any{"start_date": req.StartDate})
+		return nil, ErrPastStartDate
+	}
+
+	if s.defaultDurationMonths > 0 && (req.EndDate == nil || *req.EndDate == "") {
+		start, err := time.Parse(monthLayout, req.StartDate)
+		if err != nil {
+			return nil, newValidationError("start_date", "date must be in MM-YYYY format")
+		}
+		endDate := start.AddDate(0, s.defaultDurationMonths, 0).Format(monthLayout)
+		req.EndDate = &endDate
+	}
+
+	return s.withComputed(s.repo.Create(ctx, req))
 }
 
-func (s *service) UpdateSubscription(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error) {
+func (s *service) UpdateSubscription(ctx context.Context, id int, req UpdateSubscriptionRequest) (_ *Subscription, err error) {
+	defer s.logDuration("UpdateSubscription", time.Now(), &err)
+
 	if err := s.validateSubscriptionRequest(CreateSubscriptionRequest(req)); err != nil {
 		s.log.Warn("Validation failed", map[string]any{"error": err.Error(), "id": id})
 		return nil, err
 	}
 
-	return s.repo.Update(ctx, id, req)
+	// The repository checks the owner in the UPDATE itself, on the primary:
+	// a replica read could be stale.
+	return s.withComputed(s.repo.Update(ctx, id, req))
 }
 
 
-func (s *service) DeleteSubscription(ctx context.Context, id int) error {
-	return s.repo.Delete(ctx, id)
+func (s *service) DeleteSubscription(ctx context.Context, id int) (_ *Subscription, err error) {
+	defer s.logDuration("DeleteSubscription", time.Now(), &err)
+
+	return s.withComputed(s.repo.Delete(ctx, id))
 }
 
-func (s *service) GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
-	if startDate == "" && endDate == "" {
-		return nil, fmt.Errorf("at least one date parameter is required")
+func (s *service) GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (_ *CostResponse, err error) {
+	defer s.logDuration("GetCostByPeriod", time.Now(), &err)
+
+	startDate, endDate = s.resolveNow(startDate), s.resolveNow(endDate)
+	if err := s.validateCostPeriod(startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	if s.costGroup == nil {
+		return s.costByPeriod(ctx, startDate, endDate, userID, serviceName)
+	}
+
+	key := startDate + "|" + endDate + "|"
+	if userID != nil {
+		key += userID.String()
+	}
+	key += "|"
+	if serviceName != nil {
+		key += *serviceName
+	}
+
+	// The shared query must not be cancelled when the caller that started it goes away.
+	result, err, _ := s.costGroup.Do(key, func() (any, error) {
+		return s.costByPeriod(context.WithoutCancel(ctx), startDate, endDate, userID, serviceName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cost := *result.(*CostResponse)
+	return &cost, nil
+}
+
+func (s *service) costByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostResponse, error) {
+	totalCost, count, err := s.repo.GetCostByPeriod(ctx, startDate, endDate, userID, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CostResponse{TotalCost: totalCost, Count: count}, nil
+}
+
+// GetCostWithIDs returns the cost like GetCostByPeriod together with the IDs
+// of the subscriptions it includes. It is never deduplicated.
+func (s *service) GetCostWithIDs(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (_ *CostWithIDsResponse, err error) {
+	defer s.logDuration("GetCostWithIDs", time.Now(), &err)
+
+	startDate, endDate = s.resolveNow(startDate), s.resolveNow(endDate)
+	if err := s.validateCostPeriod(startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetCostWithIDs(ctx, startDate, endDate, userID, serviceName)
+}
+
+// GetCostByQuery returns the cost of the subscriptions matching query, validated
+// like the period of GetCostByPeriod. SubscriptionIDs is only set when
+// query.IncludeIDs is.
+func (s *service) GetCostByQuery(ctx context.Context, query CostQuery) (_ *CostWithIDsResponse, err error) {
+	defer s.logDuration("GetCostByQuery", time.Now(), &err)
+
+	if query, err = s.resolveCostQuery(query); err != nil {
+		return nil, err
+	}
+
+	if query.IncludeIDs {
+		return s.repo.GetCostByQueryWithIDs(ctx, query)
+	}
+
+	totalCost, count, err := s.repo.GetCostByQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &CostWithIDsResponse{CostResponse: CostResponse{TotalCost: totalCost, Count: count}}, nil
+}
+
+// PreviewCostChange returns the cost of the subscriptions matching req.Filter,
+// like GetCostByQuery, and what it would be if the subscriptions to
+// req.Change.ServiceName among them cost req.Change.Price. Nothing is stored.
+func (s *service) PreviewCostChange(ctx context.Context, req CostPreviewRequest) (_ *CostPreviewResponse, err error) {
+	defer s.logDuration("PreviewCostChange", time.Now(), &err)
+
+	query, err := s.resolveCostQuery(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Change.ServiceName) == "" {
+		return nil, newValidationError("change.service_name", "change.service_name is required")
+	}
+	if req.Change.Price <= 0 {
+		return nil, newValidationError("change.price", "change.price must be greater than 0")
+	}
+
+	totalCost, count, err := s.repo.GetCostByQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	preview := &CostPreviewResponse{TotalCost: totalCost, ProjectedCost: totalCost, Count: count}
+
+	// A filter on other services leaves the changed one out of the total.
+	if len(query.ServiceNames) > 0 && !slices.Contains(query.ServiceNames, req.Change.ServiceName) {
+		return preview, nil
+	}
+
+	query.ServiceNames = []string{req.Change.ServiceName}
+	changedCost, changed, err := s.repo.GetCostByQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	preview.Changed = changed
+	preview.ProjectedCost = totalCost - changedCost + int64(req.Change.Price)*int64(changed)
+	preview.Delta = preview.ProjectedCost - totalCost
+	return preview, nil
+}
+
+// resolveCostQuery resolves the dates of query set to now and validates it as
+// GetCostByQuery takes it.
+func (s *service) resolveCostQuery(query CostQuery) (CostQuery, error) {
+	query.StartDate, query.EndDate = s.resolveNow(query.StartDate), s.resolveNow(query.EndDate)
+	if err := s.validateCostPeriod(query.StartDate, query.EndDate); err != nil {
+		return CostQuery{}, err
+	}
+
+	if slices.Contains(query.UserIDs, uuid.Nil) {
+		return CostQuery{}, newValidationError("user_ids", "user_ids must be valid UUIDs")
+	}
+	if slices.Contains(query.ServiceNames, "") {
+		return CostQuery{}, newValidationError("service_names", "service_names must not be empty")
+	}
+	return query, nil
+}
+
+// GetRollingCost returns the cost of the last months months, counting the
+// current month as the last one.
+func (s *service) GetRollingCost(ctx context.Context, months int, userID *uuid.UUID) (_ *CostResponse, err error) {
+	defer s.logDuration("GetRollingCost", time.Now(), &err)
+
+	if months < 1 || months > maxRollingMonths {
+		return nil, newValidationError("months", fmt.Sprintf("months must be between 1 and %d", maxRollingMonths))
+	}
+
+	currentMonth := s.currentMonth()
+	startDate := currentMonth.AddDate(0, -(months - 1), 0).Format(monthLayout)
+	endDate := currentMonth.Format(monthLayout)
+
+	return s.GetCostByPeriod(ctx, startDate, endDate, userID, nil)
+}
+
+// GetCostVersion returns the version of the subscriptions matching the cost
+// filter, over the period with now resolved.
+func (s *service) GetCostVersion(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (_ *CostVersion, err error) {
+	defer s.logDuration("GetCostVersion", time.Now(), &err)
+
+	startDate, endDate = s.resolveNow(startDate), s.resolveNow(endDate)
+	if err := s.validateCostPeriod(startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetCostVersion(ctx, startDate, endDate, userID, serviceName)
+}
+
+func (s *service) GetServices(ctx context.Context, filter ServiceFilter, limit, offset int) (_ *Page[string], err error) {
+	defer s.logDuration("GetServices", time.Now(), &err)
+
+	limit, err = validatePage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	services, total, err := s.repo.GetServices(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(services, total, limit, offset), nil
+}
+
+func (s *service) GetSubscribers(ctx context.Context, serviceName string, limit, offset int) (_ *Page[uuid.UUID], err error) {
+	defer s.logDuration("GetSubscribers", time.Now(), &err)
+
+	if serviceName == "" {
+		return nil, newValidationError("service_name", "service_name is required")
+	}
+
+	limit, err = validatePage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, total, err := s.repo.GetSubscribers(ctx, serviceName, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(userIDs, total, limit, offset), nil
+}
+
+func (s *service) GetUsers(ctx context.Context, limit, offset int) (_ *Page[UserSubscriptions], err error) {
+	defer s.logDuration("GetUsers", time.Now(), &err)
+
+	limit, err = validatePage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.repo.GetUsers(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(users, total, limit, offset), nil
+}
+
+func (s *service) GetServiceDiversity(ctx context.Context, limit, offset int) (_ *Page[UserServices], err error) {
+	defer s.logDuration("GetServiceDiversity", time.Now(), &err)
+
+	limit, err = validatePage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.repo.GetServiceDiversity(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(users, total, limit, offset), nil
+}
+
+// GetBudgetStatus compares the monthly cost of every budgeted service with its
+// cap. A service is over budget when its cost exceeds the cap; reaching the cap
+// is still within budget. Services found over budget for the first time since
+// they were last within it emit a budget.exceeded event.
+func (s *service) GetBudgetStatus(ctx context.Context) (_ []BudgetStatus, err error) {
+	defer s.logDuration("GetBudgetStatus", time.Now(), &err)
+
+	budgets, err := s.repo.GetBudgetCosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+
+	for i := range budgets {
+		budget := &budgets[i]
+		budget.OverBudget = budget.MonthlyCost > budget.MonthlyCap
+		if !budget.OverBudget {
+			delete(s.overBudget, budget.ServiceName)
+			continue
+		}
+
+		budget.Overage = budget.MonthlyCost - budget.MonthlyCap
+		if !s.overBudget[budget.ServiceName] {
+			s.overBudget[budget.ServiceName] = true
+			s.emitBudgetExceeded(*budget)
+		}
+	}
+
+	return budgets, nil
+}
+
+// emitBudgetExceeded reports the budget.exceeded event as a warning log and a
+// metric sample.
+func (s *service) emitBudgetExceeded(budget BudgetStatus) {
+	budgetExceeded.WithLabelValues(budget.ServiceName).Inc()
+	s.log.Warn("Budget exceeded", map[string]any{
+		"event":        "budget.exceeded",
+		"service_name": budget.ServiceName,
+		"monthly_cap":  budget.MonthlyCap,
+		"monthly_cost": budget.MonthlyCost,
+		"overage":      budget.Overage,
+	})
+}
+
+// GetChangesSince returns the subscriptions created or updated after since, an
+// RFC 3339 timestamp. Deletions are not reported as subscriptions are deleted
+// for good.
+func (s *service) GetChangesSince(ctx context.Context, since string) (_ []Subscription, err error) {
+	defer s.logDuration("GetChangesSince", time.Now(), &err)
+
+	if since == "" {
+		return nil, newValidationError("since", "since is required")
+	}
+
+	sinceTime, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return nil, newValidationError("since", "since must be an RFC 3339 timestamp")
+	}
+
+	return s.withComputedList(s.repo.GetChangedSince(ctx, sinceTime))
+}
+
+// GetMeta returns the values the service accepts under its configuration.
+func (s *service) GetMeta(ctx context.Context) *MetaResponse {
+	return &MetaResponse{
+		DateFormat:         "MM-YYYY",
+		ServiceNamePattern: s.serviceNamePattern.String(),
+		SortFields:         slices.Sorted(maps.Keys(sortColumns)),
+		SortOrders:         []string{"asc", "desc"},
+		MaxPageLimit:       maxPageLimit,
+		MaxCostMonths:      s.maxCostMonths,
+	}
+}
+
+func (s *service) GetDateRange(ctx context.Context, userID *uuid.UUID) (_ *DateRangeResponse, err error) {
+	defer s.logDuration("GetDateRange", time.Now(), &err)
+
+	return s.repo.GetDateRange(ctx, userID)
+}
+
+func (s *service) DeleteUserSubscriptions(ctx context.Context, userID uuid.UUID) (_ int64, err error) {
+	defer s.logDuration("DeleteUserSubscriptions", time.Now(), &err)
+
+	if userID == uuid.Nil {
+		return 0, newValidationError("user_id", "user_id is required and must be valid UUID")
 	}
 
-	if err := s.validateDateFormat(startDate); err != nil {
+	return s.repo.DeleteByUser(ctx, userID)
+}
+
+// CancelUserSubscriptions ends every subscription of the user active in the
+// current month with the current month, keeping them for history.
+func (s *service) CancelUserSubscriptions(ctx context.Context, userID uuid.UUID) (_ int64, err error) {
+	defer s.logDuration("CancelUserSubscriptions", time.Now(), &err)
+
+	if userID == uuid.Nil {
+		return 0, newValidationError("user_id", "user_id is required and must be valid UUID")
+	}
+
+	return s.repo.CancelByUser(ctx, userID)
+}
+
+func (s *service) RenewSubscription(ctx context.Context, id int, req RenewSubscriptionRequest) (_ *Subscription, err error) {
+	defer s.logDuration("RenewSubscription", time.Now(), &err)
+
+	if err := s.validateDateFormat("start_date", req.StartDate); err != nil {
+		s.log.Warn("Validation failed", map[string]any{"error": err.Error(), "id": id})
 		return nil, err
 	}
 
-	if endDate != "" {
-		if err := s.validateDateFormat(endDate); err != nil {
+	if req.EndDate != nil && *req.EndDate != "" {
+		if err := s.validateDateFormat("end_date", *req.EndDate); err != nil {
+			s.log.Warn("Validation failed", map[string]any{"error": err.Error(), "id": id})
 			return nil, err
 		}
+	} else {
+		req.EndDate = nil
 	}
 
-	totalCost, count, err := s.repo.GetCostByPeriod(ctx, startDate, endDate, userID, serviceName)
+	return s.withComputed(s.repo.Renew(ctx, id, req))
+}
+
+func (s *service) ExportSubscriptions(ctx context.Context, filter SubscriptionFilter) (_ []Subscription, err error) {
+	defer s.logDuration("ExportSubscriptions", time.Now(), &err)
+
+	if filter.StartDate != "" {
+		if err := s.validateDateFormat("start_date", filter.StartDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.EndDate != "" {
+		if err := s.validateDateFormat("end_date", filter.EndDate); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.withComputedList(s.repo.Export(ctx, filter))
+}
+
+// resolveNow replaces the date nowDate with the current month in MM-YYYY
+// format and returns other dates unchanged.
+func (s *service) resolveNow(date string) string {
+	if date == nowDate {
+		return s.currentMonth().Format(monthLayout)
+	}
+	return date
+}
+
+// currentMonth returns the first day of the current month in UTC.
+func (s *service) currentMonth() time.Time {
+	now := s.now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// logDuration logs the outcome of the service method op started at start with
+// its duration in milliseconds. Methods defer it with a pointer to their error
+// result. Errors caused by the request are logged as warnings.
+func (s *service) logDuration(op string, start time.Time, err *error) {
+	fields := map[string]any{"operation": op, "duration_ms": time.Since(start).Milliseconds()}
+	if *err != nil {
+		fields["error"] = *err
+		if isClientError(*err) {
+			s.log.Warn("Service call rejected", fields)
+			return
+		}
+		s.log.Error("Service call failed", fields)
+		return
+	}
+	s.log.Info("Service call completed", fields)
+}
+
+// clientErrors are the errors answering a request that cannot be served as
+// asked, rather than a failure of the service.
+var clientErrors = []error{ErrNotFound, ErrExternalIDExists, ErrUserIDImmutable, ErrSummaryNotFound, ErrPastStartDate}
+
+// isClientError reports whether err is a ValidationError or one of
+// clientErrors.
+func isClientError(err error) bool {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return true
+	}
+	return slices.ContainsFunc(clientErrors, func(target error) bool { return errors.Is(err, target) })
+}
+
+// withComputed sets the computed fields of sub, Active and NextBillingDate, as
+// of the current month and passes the repository result through.
+func (s *service) withComputed(sub *Subscription, err error) (*Subscription, error) {
+	if sub != nil {
+		sub.setComputed(s.currentMonth())
+	}
+	return sub, err
+}
+
+// withComputedList is withComputed for a list of subscriptions.
+func (s *service) withComputedList(subs []Subscription, err error) ([]Subscription, error) {
+	month := s.currentMonth()
+	for i := range subs {
+		subs[i].setComputed(month)
+	}
+	return subs, err
+}
+
+// newPage wraps the items found at offset into a Page. NextCursor is set while
+// items remain after them.
+func newPage[T any](items []T, total, limit, offset int) *Page[T] {
+	page := &Page[T]{Items: items, Total: total, Limit: limit, Offset: offset}
+	if next := offset + len(items); len(items) > 0 && next < total {
+		page.NextCursor = strconv.Itoa(next)
+	}
+	return page
+}
+
+// validatePage checks pagination parameters and returns the limit to use,
+// applying the default when it is zero.
+func validatePage(limit, offset int) (int, error) {
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+
+	if limit < 0 || limit > maxPageLimit {
+		return 0, newValidationError("limit", fmt.Sprintf("limit must be between 1 and %d", maxPageLimit))
+	}
+
+	if offset < 0 {
+		return 0, newValidationError("offset", "offset must not be negative")
+	}
+
+	return limit, nil
+}
+
+// validateCostPeriod checks the period of a cost query:
+//   - start and end: subscriptions starting from start_date and not ended before
+//     end_date; end_date must not precede start_date and, with a maximum set,
+//     the period must not exceed maxCostMonths;
+//   - start only: subscriptions starting from start_date, with no upper bound;
+//   - end only: rejected, an unbounded start would silently cover all history;
+//   - neither: rejected.
+func (s *service) validateCostPeriod(startDate, endDate string) error {
+	if startDate == "" && endDate == "" {
+		return newValidationError("start_date", "at least one date parameter is required")
+	}
+
+	if startDate == "" {
+		return newValidationError("start_date", "start_date is required when end_date is set")
+	}
+
+	if err := s.validateDateFormat("start_date", startDate); err != nil {
+		return err
+	}
+
+	if endDate == "" {
+		return nil
+	}
+
+	if err := s.validateDateFormat("end_date", endDate); err != nil {
+		return err
+	}
+
+	start, err := time.Parse(monthLayout, startDate)
+	if err != nil {
+		return newValidationError("start_date", "date must be in MM-YYYY format")
+	}
+	end, err := time.Parse(monthLayout, endDate)
+	if err != nil {
+		return newValidationError("end_date", "date must be in MM-YYYY format")
+	}
+
+	if end.Before(start) {
+		return newValidationError("end_date", "end_date must not be before start_date")
+	}
+
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+	if s.maxCostMonths > 0 && months > s.maxCostMonths {
+		return newValidationError("end_date", "date range too large")
+	}
+
+	return nil
+}
+
+// GetStats returns the subscription aggregates. With the stats cache enabled
+// the cached snapshot is returned unless fresh is set or none exists yet;
+// recomputed stats replace the snapshot.
+func (s *service) GetStats(ctx context.Context, fresh bool) (_ *StatsResponse, err error) {
+	defer s.logDuration("GetStats", time.Now(), &err)
+
+	if s.cacheStats && !fresh {
+		s.statsMu.RLock()
+		snapshot := s.statsSnapshot
+		s.statsMu.RUnlock()
+
+		if snapshot != nil {
+			stats := *snapshot
+			return &stats, nil
+		}
+	}
+
+	stats, err := s.repo.GetStats(ctx)
 	if err != nil {
 		return nil, err
 	}
+	stats.ComputedAt = s.now().UTC()
 
-	return &CostResponse{TotalCost: totalCost, Count: count}, nil
+	if s.cacheStats {
+		snapshot := *stats
+		s.statsMu.Lock()
+		s.statsSnapshot = &snapshot
+		s.statsMu.Unlock()
+	}
+
+	return stats, nil
+}
+
+// RecomputeSummaries refreshes the per-user cost summaries served by
+// GetUserSummary.
+func (s *service) RecomputeSummaries(ctx context.Context) (_ *RecomputeSummariesResponse, err error) {
+	defer s.logDuration("RecomputeSummaries", time.Now(), &err)
+
+	users, err := s.repo.RecomputeSummaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &RecomputeSummariesResponse{Users: users}, nil
+}
+
+// GetUserSummary returns the cost summary of the user as of the last
+// recompute, without querying the subscriptions.
+func (s *service) GetUserSummary(ctx context.Context, userID uuid.UUID) (_ *UserCostSummary, err error) {
+	defer s.logDuration("GetUserSummary", time.Now(), &err)
+
+	return s.repo.GetUserSummary(ctx, userID)
+}
+
+// ValidateSubscription checks req as CreateSubscription would and returns every
+// violation found, without touching the repository. In strict mode a start
+// before the current month is reported as a start_date violation. The
+// violations of this dry run are not counted in the validation failures metric.
+func (s *service) ValidateSubscription(ctx context.Context, req CreateSubscriptionRequest) []*ValidationError {
+	violations := s.subscriptionViolations(req)
+	if s.startsInPast(req.StartDate) {
+		violations = append(violations, &ValidationError{Field: "start_date", Message: ErrPastStartDate.Error()})
+	}
+	return violations
+}
+
+// startsInPast reports whether a create starting at startDate is rejected
+// with ErrPastStartDate, see WithRejectPastStart. A start_date that does not
+// parse is left to the format check.
+func (s *service) startsInPast(startDate string) bool {
+	if !s.rejectPastStart {
+		return false
+	}
+	start, err := time.Parse(monthLayout, startDate)
+	return err == nil && start.Before(s.currentMonth())
+}
+
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
 }
 
+// validateSubscriptionRequest counts every violation of req in the validation
+// failures metric and returns the first one, if any.
 func (s *service) validateSubscriptionRequest(req CreateSubscriptionRequest) error {
-	if req.ServiceName == "" {
-		return fmt.Errorf("service_name is required")
+	violations := s.subscriptionViolations(req)
+	for _, violation := range violations {
+		_ = countValidationError(violation)
+	}
+	if len(violations) > 0 {
+		return violations[0]
+	}
+	return nil
+}
+
+// subscriptionViolations checks every field of req and returns all violations
+// in field order. They are not counted: callers decide whether they are.
+func (s *service) subscriptionViolations(req CreateSubscriptionRequest) []*ValidationError {
+	var violations []*ValidationError
+	add := func(violation *ValidationError) {
+		if violation != nil {
+			violations = append(violations, violation)
+		}
+	}
+
+	// Control characters and names without a letter or digit break display
+	// and search, whatever the configured pattern allows.
+	switch {
+	case req.ServiceName == "":
+		add(&ValidationError{Field: "service_name", Message: "service_name is required"})
+	case strings.ContainsFunc(req.ServiceName, unicode.IsControl):
+		add(&ValidationError{Field: "service_name", Message: "service_name must not contain control characters"})
+	case !strings.ContainsFunc(req.ServiceName, isLetterOrDigit):
+		add(&ValidationError{Field: "service_name", Message: "service_name must contain a letter or digit"})
+	case !s.serviceNamePattern.MatchString(req.ServiceName):
+		add(&ValidationError{Field: "service_name", Message: "service_name contains disallowed characters"})
 	}
 
 	if req.Price <= 0 {
-		return fmt.Errorf("price must be greater than 0")
+		add(&ValidationError{Field: "price", Message: "price must be greater than 0"})
 	}
 
 	if req.UserID == uuid.Nil {
-		return fmt.Errorf("user_id is required and must be valid UUID")
+		add(&ValidationError{Field: "user_id", Message: "user_id is required and must be valid UUID"})
 	}
 
-	if err := s.validateDateFormat(req.StartDate); err != nil {
-		return err
-	}
+	add(dateFormatViolation("start_date", req.StartDate))
 
 	if req.EndDate != nil && *req.EndDate != "" {
-		if err := s.validateDateFormat(*req.EndDate); err != nil {
-			return err
-		}
+		add(dateFormatViolation("end_date", *req.EndDate))
+	}
+
+	if req.ExternalID != nil && *req.ExternalID == "" {
+		add(&ValidationError{Field: "external_id", Message: "external_id must not be empty"})
 	}
 
+	return violations
+}
+
+func (s *service) validateDateFormat(field, date string) error {
+	if violation := dateFormatViolation(field, date); violation != nil {
+		return countValidationError(violation)
+	}
 	return nil
 }
 
-func (s *service) validateDateFormat(date string) error {
+// dateFormatViolation returns the violation of a field holding a MM-YYYY
+// date, or nil when date is well-formed.
+func dateFormatViolation(field, date string) *ValidationError {
 	if date == "" {
-		return fmt.Errorf("date cannot be empty")
+		return &ValidationError{Field: field, Message: "date cannot be empty"}
 	}
 
 	pattern := `^\d{2}-\d{4}$`
 	matched, err := regexp.MatchString(pattern, date)
 	if err != nil || !matched {
-		return fmt.Errorf("date must be in MM-YYYY format")
+		return &ValidationError{Field: field, Message: "date must be in MM-YYYY format"}
+	}
+
+	if _, err := time.Parse(monthLayout, date); err != nil {
+		return &ValidationError{Field: field, Message: "month must be between 01 and 12"}
 	}
 
 	return nil
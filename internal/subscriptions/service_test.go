@@ -2,24 +2,51 @@ package subscriptions
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockRepository struct {
-	GetAllFunc          func(ctx context.Context) ([]Subscription, error)
-	GetByIDFunc         func(ctx context.Context, id int) (*Subscription, error)
-	CreateFunc          func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error)
-	UpdateFunc          func(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error)
-	DeleteFunc          func(ctx context.Context, id int) error
-	GetCostByPeriodFunc func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int, int, error)
+	GetAllFunc                func(ctx context.Context, sort Sort, limit int) ([]Subscription, error)
+	GetByIDFunc               func(ctx context.Context, id int) (*Subscription, error)
+	CreateFunc                func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error)
+	UpdateFunc                func(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error)
+	DeleteFunc                func(ctx context.Context, id int) (*Subscription, error)
+	GetCostByPeriodFunc       func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error)
+	GetCostVersionFunc        func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error)
+	GetServicesFunc           func(ctx context.Context, filter ServiceFilter, limit, offset int) ([]string, int, error)
+	GetDateRangeFunc          func(ctx context.Context, userID *uuid.UUID) (*DateRangeResponse, error)
+	DeleteByUserFunc          func(ctx context.Context, userID uuid.UUID) (int64, error)
+	RenewFunc                 func(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error)
+	ExportFunc                func(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error)
+	GetSubscribersFunc        func(ctx context.Context, serviceName string, limit, offset int) ([]uuid.UUID, int, error)
+	GetStatsFunc              func(ctx context.Context) (*StatsResponse, error)
+	GetUsersFunc              func(ctx context.Context, limit, offset int) ([]UserSubscriptions, int, error)
+	GetByExternalIDFunc       func(ctx context.Context, externalID string) (*Subscription, error)
+	GetCostWithIDsFunc        func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error)
+	GetChangedSinceFunc       func(ctx context.Context, since time.Time) ([]Subscription, error)
+	RecomputeSummariesFunc    func(ctx context.Context) (int64, error)
+	GetUserSummaryFunc        func(ctx context.Context, userID uuid.UUID) (*UserCostSummary, error)
+	GetServiceDiversityFunc   func(ctx context.Context, limit, offset int) ([]UserServices, int, error)
+	GetBudgetCostsFunc        func(ctx context.Context) ([]BudgetStatus, error)
+	CancelByUserFunc          func(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetCostByQueryFunc        func(ctx context.Context, query CostQuery) (int64, int, error)
+	GetCostByQueryWithIDsFunc func(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error)
 }
 
-func (m *MockRepository) GetAll(ctx context.Context) ([]Subscription, error) {
+func (m *MockRepository) GetAll(ctx context.Context, sort Sort, limit int) ([]Subscription, error) {
 	if m.GetAllFunc != nil {
-		return m.GetAllFunc(ctx)
+		return m.GetAllFunc(ctx, sort, limit)
 	}
 	return []Subscription{}, nil
 }
@@ -59,20 +86,158 @@ func (m *MockRepository) Update(ctx context.Context, id int, req UpdateSubscript
 	}, nil
 }
 
-func (m *MockRepository) Delete(ctx context.Context, id int) error {
+func (m *MockRepository) Delete(ctx context.Context, id int) (*Subscription, error) {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(ctx, id)
 	}
-	return nil
+	return &Subscription{ID: id}, nil
 }
 
-func (m *MockRepository) GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int, int, error) {
+func (m *MockRepository) GetCostByPeriod(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
 	if m.GetCostByPeriodFunc != nil {
 		return m.GetCostByPeriodFunc(ctx, startDate, endDate, userID, serviceName)
 	}
 	return 0, 0, nil
 }
 
+func (m *MockRepository) GetServices(ctx context.Context, filter ServiceFilter, limit, offset int) ([]string, int, error) {
+	if m.GetServicesFunc != nil {
+		return m.GetServicesFunc(ctx, filter, limit, offset)
+	}
+	return []string{}, 0, nil
+}
+
+func (m *MockRepository) GetDateRange(ctx context.Context, userID *uuid.UUID) (*DateRangeResponse, error) {
+	if m.GetDateRangeFunc != nil {
+		return m.GetDateRangeFunc(ctx, userID)
+	}
+	return &DateRangeResponse{}, nil
+}
+
+func (m *MockRepository) CancelByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if m.CancelByUserFunc != nil {
+		return m.CancelByUserFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+func (m *MockRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if m.DeleteByUserFunc != nil {
+		return m.DeleteByUserFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+func (m *MockRepository) Renew(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error) {
+	if m.RenewFunc != nil {
+		return m.RenewFunc(ctx, id, req)
+	}
+	return &Subscription{
+		ID:            id + 1,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		RenewedFromID: &id,
+	}, nil
+}
+
+func (m *MockRepository) GetCostVersion(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostVersion, error) {
+	if m.GetCostVersionFunc != nil {
+		return m.GetCostVersionFunc(ctx, startDate, endDate, userID, serviceName)
+	}
+	return &CostVersion{StartDate: startDate, EndDate: endDate}, nil
+}
+
+func (m *MockRepository) GetCostByQuery(ctx context.Context, query CostQuery) (int64, int, error) {
+	if m.GetCostByQueryFunc != nil {
+		return m.GetCostByQueryFunc(ctx, query)
+	}
+	return 0, 0, nil
+}
+
+func (m *MockRepository) GetCostByQueryWithIDs(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error) {
+	if m.GetCostByQueryWithIDsFunc != nil {
+		return m.GetCostByQueryWithIDsFunc(ctx, query)
+	}
+	return &CostWithIDsResponse{SubscriptionIDs: []int{}}, nil
+}
+
+func (m *MockRepository) GetCostWithIDs(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error) {
+	if m.GetCostWithIDsFunc != nil {
+		return m.GetCostWithIDsFunc(ctx, startDate, endDate, userID, serviceName)
+	}
+	return &CostWithIDsResponse{SubscriptionIDs: []int{}}, nil
+}
+
+func (m *MockRepository) Export(ctx context.Context, filter SubscriptionFilter) ([]Subscription, error) {
+	if m.ExportFunc != nil {
+		return m.ExportFunc(ctx, filter)
+	}
+	return []Subscription{}, nil
+}
+
+func (m *MockRepository) GetSubscribers(ctx context.Context, serviceName string, limit, offset int) ([]uuid.UUID, int, error) {
+	if m.GetSubscribersFunc != nil {
+		return m.GetSubscribersFunc(ctx, serviceName, limit, offset)
+	}
+	return []uuid.UUID{}, 0, nil
+}
+
+func (m *MockRepository) GetStats(ctx context.Context) (*StatsResponse, error) {
+	if m.GetStatsFunc != nil {
+		return m.GetStatsFunc(ctx)
+	}
+	return &StatsResponse{}, nil
+}
+
+func (m *MockRepository) GetUsers(ctx context.Context, limit, offset int) ([]UserSubscriptions, int, error) {
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc(ctx, limit, offset)
+	}
+	return []UserSubscriptions{}, 0, nil
+}
+
+func (m *MockRepository) GetServiceDiversity(ctx context.Context, limit, offset int) ([]UserServices, int, error) {
+	if m.GetServiceDiversityFunc != nil {
+		return m.GetServiceDiversityFunc(ctx, limit, offset)
+	}
+	return []UserServices{}, 0, nil
+}
+
+func (m *MockRepository) GetBudgetCosts(ctx context.Context) ([]BudgetStatus, error) {
+	if m.GetBudgetCostsFunc != nil {
+		return m.GetBudgetCostsFunc(ctx)
+	}
+	return []BudgetStatus{}, nil
+}
+
+func (m *MockRepository) GetByExternalID(ctx context.Context, externalID string) (*Subscription, error) {
+	if m.GetByExternalIDFunc != nil {
+		return m.GetByExternalIDFunc(ctx, externalID)
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockRepository) GetChangedSince(ctx context.Context, since time.Time) ([]Subscription, error) {
+	if m.GetChangedSinceFunc != nil {
+		return m.GetChangedSinceFunc(ctx, since)
+	}
+	return []Subscription{}, nil
+}
+
+func (m *MockRepository) RecomputeSummaries(ctx context.Context) (int64, error) {
+	if m.RecomputeSummariesFunc != nil {
+		return m.RecomputeSummariesFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *MockRepository) GetUserSummary(ctx context.Context, userID uuid.UUID) (*UserCostSummary, error) {
+	if m.GetUserSummaryFunc != nil {
+		return m.GetUserSummaryFunc(ctx, userID)
+	}
+	return nil, ErrSummaryNotFound
+}
+
 type MockLogger struct{}
 
 func (m *MockLogger) Info(message string, fields map[string]any)  {}
@@ -82,6 +247,37 @@ func (m *MockLogger) Debug(message string, fields map[string]any) {}
 func (m *MockLogger) Fatal(message string, fields map[string]any) {}
 func (m *MockLogger) Sync() error                                         { return nil }
 
+// recordingLogger is a MockLogger keeping the info, warning and error log entries.
+type recordingLogger struct {
+	MockLogger
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+type logEntry struct {
+	level   string
+	message string
+	fields  map[string]any
+}
+
+func (l *recordingLogger) Info(message string, fields map[string]any) {
+	l.record("info", message, fields)
+}
+
+func (l *recordingLogger) Warn(message string, fields map[string]any) {
+	l.record("warn", message, fields)
+}
+
+func (l *recordingLogger) Error(message string, fields map[string]any) {
+	l.record("error", message, fields)
+}
+
+func (l *recordingLogger) record(level, message string, fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, message: message, fields: fields})
+}
+
 func TestServiceCreateSubscription_Success(t *testing.T) {
 	mockRepo := &MockRepository{}
 	mockLog := &MockLogger{}
@@ -148,6 +344,17 @@ func TestCreateSubscription_Validation(t *testing.T) {
 			},
 			errMsg: "date must be in MM-YYYY format",
 		},
+		{
+			name: "Empty external ID",
+			req: CreateSubscriptionRequest{
+				ServiceName: "Netflix",
+				Price:       100,
+				UserID:      uuid.New(),
+				StartDate:   "01-2025",
+				ExternalID:  new(string),
+			},
+			errMsg: "external_id must not be empty",
+		},
 	}
 
 	for _, tt := range tests {
@@ -189,9 +396,25 @@ func TestServiceDeleteSubscription(t *testing.T) {
 	mockLog := &MockLogger{}
 	svc := NewService(mockRepo, mockLog)
 
-	err := svc.DeleteSubscription(context.Background(), 1)
+	sub, err := svc.DeleteSubscription(context.Background(), 1)
 	
 	assert.NoError(t, err)
+	assert.Equal(t, 1, sub.ID)
+}
+
+func TestServiceDeleteSubscription_NotFound(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	mockRepo.DeleteFunc = func(ctx context.Context, id int) (*Subscription, error) {
+		return nil, ErrNotFound
+	}
+
+	sub, err := svc.DeleteSubscription(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, sub)
 }
 
 func TestServiceGetCostByPeriod_Success(t *testing.T) {
@@ -199,7 +422,7 @@ func TestServiceGetCostByPeriod_Success(t *testing.T) {
 	mockLog := &MockLogger{}
 	svc := NewService(mockRepo, mockLog)
 
-	mockRepo.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int, int, error) {
+	mockRepo.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
 		return 1200, 12, nil
 	}
 
@@ -207,7 +430,7 @@ func TestServiceGetCostByPeriod_Success(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, 1200, result.TotalCost)
+	assert.Equal(t, int64(1200), result.TotalCost)
 	assert.Equal(t, 12, result.Count)
 }
 
@@ -230,6 +453,18 @@ func TestGetCostByPeriod_Validation(t *testing.T) {
 			endDate:   "",
 			errMsg:    "date must be in MM-YYYY format",
 		},
+		{
+			name:      "Month above 12",
+			startDate: "13-2025",
+			endDate:   "",
+			errMsg:    "month must be between 01 and 12",
+		},
+		{
+			name:      "Month zero",
+			startDate: "01-2025",
+			endDate:   "00-2025",
+			errMsg:    "month must be between 01 and 12",
+		},
 	}
 
 	for _, tt := range tests {
@@ -245,4 +480,1421 @@ func TestGetCostByPeriod_Validation(t *testing.T) {
 			assert.Nil(t, result)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestServiceGetServices_Pagination(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	var gotFilter ServiceFilter
+	var gotLimit, gotOffset int
+	mockRepo.GetServicesFunc = func(ctx context.Context, filter ServiceFilter, limit, offset int) ([]string, int, error) {
+		gotFilter, gotLimit, gotOffset = filter, limit, offset
+		return []string{"Netflix"}, 41, nil
+	}
+
+	services, err := svc.GetServices(context.Background(), ServiceFilter{Prefix: "net"}, 0, 40)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Page[string]{Items: []string{"Netflix"}, Total: 41, Limit: defaultPageLimit, Offset: 40}, services)
+	assert.Equal(t, ServiceFilter{Prefix: "net"}, gotFilter)
+	assert.Equal(t, defaultPageLimit, gotLimit)
+	assert.Equal(t, 40, gotOffset)
+}
+
+func TestGetServices_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		offset int
+		errMsg string
+	}{
+		{
+			name:   "Negative limit",
+			limit:  -1,
+			errMsg: "limit must be between 1 and 100",
+		},
+		{
+			name:   "Limit too large",
+			limit:  maxPageLimit + 1,
+			errMsg: "limit must be between 1 and 100",
+		},
+		{
+			name:   "Negative offset",
+			limit:  10,
+			offset: -5,
+			errMsg: "offset must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			mockLog := &MockLogger{}
+			svc := NewService(mockRepo, mockLog)
+
+			services, err := svc.GetServices(context.Background(), ServiceFilter{}, tt.limit, tt.offset)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.Nil(t, services)
+		})
+	}
+}
+
+func TestServiceCreateSubscription_DefaultDuration(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog, WithDefaultDurationMonths(12))
+
+	req := CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       100,
+		UserID:      uuid.New(),
+		StartDate:   "03-2025",
+	}
+
+	sub, err := svc.CreateSubscription(context.Background(), req)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, sub.EndDate) {
+		assert.Equal(t, "03-2026", *sub.EndDate)
+	}
+
+	endDate := "06-2025"
+	req.EndDate = &endDate
+	sub, err = svc.CreateSubscription(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "06-2025", *sub.EndDate)
+}
+
+func TestServiceCreateSubscription_DefaultDurationDisabled(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	req := CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       100,
+		UserID:      uuid.New(),
+		StartDate:   "03-2025",
+	}
+
+	sub, err := svc.CreateSubscription(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Nil(t, sub.EndDate)
+}
+
+func TestServiceCreateSubscription_RejectPastStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ServiceOption
+		startDate string
+		wantErr   error
+	}{
+		{name: "Past start in strict mode", opts: []ServiceOption{WithRejectPastStart()}, startDate: "05-2025", wantErr: ErrPastStartDate},
+		{name: "Current month in strict mode", opts: []ServiceOption{WithRejectPastStart()}, startDate: "06-2025"},
+		{name: "Future start in strict mode", opts: []ServiceOption{WithRejectPastStart()}, startDate: "01-2026"},
+		{name: "Past start without strict mode", startDate: "05-2025"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			mockLog := &MockLogger{}
+			svc := NewService(mockRepo, mockLog, tt.opts...).(*service)
+			svc.now = func() time.Time { return time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC) }
+
+			var created bool
+			mockRepo.CreateFunc = func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+				created = true
+				return &Subscription{ID: 1, StartDate: req.StartDate}, nil
+			}
+
+			sub, err := svc.CreateSubscription(context.Background(), CreateSubscriptionRequest{
+				ServiceName: "Netflix",
+				Price:       100,
+				UserID:      uuid.New(),
+				StartDate:   tt.startDate,
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, sub)
+				assert.False(t, created)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.startDate, sub.StartDate)
+			assert.True(t, created)
+		})
+	}
+}
+
+func TestServiceRenewSubscription_Success(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	endDate := "12-2026"
+	sub, err := svc.RenewSubscription(context.Background(), 7, RenewSubscriptionRequest{StartDate: "01-2026", EndDate: &endDate})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "01-2026", sub.StartDate)
+	if assert.NotNil(t, sub.RenewedFromID) {
+		assert.Equal(t, 7, *sub.RenewedFromID)
+	}
+}
+
+func TestServiceRenewSubscription_InvalidDate(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	mockRepo.RenewFunc = func(ctx context.Context, id int, req RenewSubscriptionRequest) (*Subscription, error) {
+		t.Fatal("repository must not be called for invalid input")
+		return nil, nil
+	}
+
+	sub, err := svc.RenewSubscription(context.Background(), 7, RenewSubscriptionRequest{StartDate: "2026-01"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "date must be in MM-YYYY format")
+	assert.Nil(t, sub)
+}
+
+func TestServiceUpdateSubscription_UserIDChangeRejected(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	mockRepo.GetByIDFunc = func(ctx context.Context, id int) (*Subscription, error) {
+		t.Fatal("the owner must be checked by the update, not a replica read")
+		return nil, nil
+	}
+	mockRepo.UpdateFunc = func(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error) {
+		return nil, ErrUserIDImmutable
+	}
+
+	sub, err := svc.UpdateSubscription(context.Background(), 1, UpdateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       100,
+		UserID:      uuid.New(),
+		StartDate:   "01-2025",
+	})
+
+	assert.ErrorIs(t, err, ErrUserIDImmutable)
+	assert.Nil(t, sub)
+}
+
+func TestServiceUpdateSubscription_NotFound(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	mockRepo.UpdateFunc = func(ctx context.Context, id int, req UpdateSubscriptionRequest) (*Subscription, error) {
+		return nil, ErrNotFound
+	}
+
+	sub, err := svc.UpdateSubscription(context.Background(), 1, UpdateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       100,
+		UserID:      uuid.New(),
+		StartDate:   "01-2025",
+	})
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, sub)
+}
+
+func TestServiceGetCostVersion_ValidatesPeriod(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	version, err := svc.GetCostVersion(context.Background(), "", "", nil, nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "at least one date parameter is required")
+	assert.Nil(t, version)
+}
+
+func TestValidationFailures_CountedByField(t *testing.T) {
+	invalidEnd := "2025-12"
+
+	tests := []struct {
+		name  string
+		field string
+		call  func(svc SubscriptionService) error
+	}{
+		{
+			name:  "Missing service name",
+			field: "service_name",
+			call: func(svc SubscriptionService) error {
+				_, err := svc.CreateSubscription(context.Background(), CreateSubscriptionRequest{Price: 100, UserID: uuid.New(), StartDate: "01-2025"})
+				return err
+			},
+		},
+		{
+			name:  "Non-positive price",
+			field: "price",
+			call: func(svc SubscriptionService) error {
+				_, err := svc.CreateSubscription(context.Background(), CreateSubscriptionRequest{ServiceName: "Netflix", UserID: uuid.New(), StartDate: "01-2025"})
+				return err
+			},
+		},
+		{
+			name:  "Missing user ID",
+			field: "user_id",
+			call: func(svc SubscriptionService) error {
+				_, err := svc.CreateSubscription(context.Background(), CreateSubscriptionRequest{ServiceName: "Netflix", Price: 100, StartDate: "01-2025"})
+				return err
+			},
+		},
+		{
+			name:  "Invalid start date",
+			field: "start_date",
+			call: func(svc SubscriptionService) error {
+				_, err := svc.CreateSubscription(context.Background(), CreateSubscriptionRequest{ServiceName: "Netflix", Price: 100, UserID: uuid.New(), StartDate: "2025-01"})
+				return err
+			},
+		},
+		{
+			name:  "Invalid end date",
+			field: "end_date",
+			call: func(svc SubscriptionService) error {
+				_, err := svc.CreateSubscription(context.Background(), CreateSubscriptionRequest{ServiceName: "Netflix", Price: 100, UserID: uuid.New(), StartDate: "01-2025", EndDate: &invalidEnd})
+				return err
+			},
+		},
+		{
+			name:  "Invalid services limit",
+			field: "limit",
+			call: func(svc SubscriptionService) error {
+				_, err := svc.GetServices(context.Background(), ServiceFilter{}, -1, 0)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			mockLog := &MockLogger{}
+			svc := NewService(mockRepo, mockLog)
+
+			before := testutil.ToFloat64(validationFailures.WithLabelValues(tt.field))
+
+			err := tt.call(svc)
+
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.field, validationErr.Field)
+			}
+			assert.Equal(t, before+1, testutil.ToFloat64(validationFailures.WithLabelValues(tt.field)))
+		})
+	}
+}
+
+func TestServiceExportSubscriptions_InvalidDate(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	subs, err := svc.ExportSubscriptions(context.Background(), SubscriptionFilter{EndDate: "2025-12"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "date must be in MM-YYYY format")
+	assert.Nil(t, subs)
+}
+
+func TestServiceGetAllSubscriptions_InvalidSort(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	tests := []struct {
+		name  string
+		sort  Sort
+		field string
+	}{
+		{name: "Unknown field", sort: Sort{Field: "password"}, field: "sort_by"},
+		{name: "Injection attempt", sort: Sort{Field: "price; DROP TABLE subscriptions"}, field: "sort_by"},
+		{name: "Invalid order", sort: Sort{Field: "price", Order: "sideways"}, field: "order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, err := svc.GetAllSubscriptions(context.Background(), tt.sort)
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.field, validationErr.Field)
+			assert.Nil(t, subs)
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	assert.Equal(t, "ORDER BY created_at DESC, id DESC", orderBy(Sort{}))
+	assert.Equal(t, "ORDER BY price DESC, id DESC", orderBy(Sort{Field: "price", Order: "desc"}))
+	assert.Equal(t, "ORDER BY month_date(start_date) ASC, id ASC", orderBy(Sort{Field: "start_date"}))
+	assert.Equal(t, "ORDER BY created_at DESC, id DESC", orderBy(Sort{Field: "price; DROP TABLE subscriptions"}))
+}
+
+func TestServiceGetSubscribers(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	var gotLimit int
+	mockRepo.GetSubscribersFunc = func(ctx context.Context, serviceName string, limit, offset int) ([]uuid.UUID, int, error) {
+		gotLimit = limit
+		return []uuid.UUID{}, 0, nil
+	}
+
+	userIDs, err := svc.GetSubscribers(context.Background(), "Netflix", 0, 0)
+
+	assert.NoError(t, err)
+	assert.Empty(t, userIDs.Items)
+	assert.Equal(t, defaultPageLimit, gotLimit)
+}
+
+func TestServiceGetSubscribers_MissingServiceName(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	userIDs, err := svc.GetSubscribers(context.Background(), "", 10, 0)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "service_name is required")
+	assert.Nil(t, userIDs)
+}
+
+func TestServiceGetRollingCost_MatchesExplicitRange(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog).(*service)
+	svc.now = func() time.Time { return time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC) }
+
+	type call struct {
+		startDate, endDate string
+		userID             *uuid.UUID
+	}
+	var calls []call
+	mockRepo.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
+		calls = append(calls, call{startDate, endDate, userID})
+		return 1200, 3, nil
+	}
+
+	userID := uuid.New()
+	rolling, err := svc.GetRollingCost(context.Background(), 12, &userID)
+	assert.NoError(t, err)
+
+	explicit, err := svc.GetCostByPeriod(context.Background(), "07-2024", "06-2025", &userID, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, explicit, rolling)
+	assert.Len(t, calls, 2)
+	assert.Equal(t, calls[1], calls[0])
+
+	_, err = svc.GetRollingCost(context.Background(), 1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "06-2025", calls[2].startDate)
+	assert.Equal(t, "06-2025", calls[2].endDate)
+}
+
+func TestServiceGetCostByPeriod_Now(t *testing.T) {
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		wantStart string
+		wantEnd   string
+	}{
+		{name: "End date now", startDate: "01-2025", endDate: "now", wantStart: "01-2025", wantEnd: "06-2025"},
+		{name: "Start date now", startDate: "now", endDate: "12-2025", wantStart: "06-2025", wantEnd: "12-2025"},
+		{name: "Both now", startDate: "now", endDate: "now", wantStart: "06-2025", wantEnd: "06-2025"},
+		{name: "Start date now without end", startDate: "now", wantStart: "06-2025"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			mockLog := &MockLogger{}
+			svc := NewService(mockRepo, mockLog).(*service)
+			svc.now = func() time.Time { return time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC) }
+
+			var gotStart, gotEnd string
+			mockRepo.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
+				gotStart, gotEnd = startDate, endDate
+				return 600, 2, nil
+			}
+
+			cost, err := svc.GetCostByPeriod(context.Background(), tt.startDate, tt.endDate, nil, nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &CostResponse{TotalCost: 600, Count: 2}, cost)
+			assert.Equal(t, tt.wantStart, gotStart)
+			assert.Equal(t, tt.wantEnd, gotEnd)
+		})
+	}
+}
+
+func TestServiceGetCostByPeriod_NowBeforeStart(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog).(*service)
+	svc.now = func() time.Time { return time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC) }
+
+	cost, err := svc.GetCostByPeriod(context.Background(), "07-2025", "now", nil, nil)
+
+	assert.EqualError(t, err, "end_date must not be before start_date")
+	assert.Nil(t, cost)
+}
+
+func TestServiceGetRollingCost_InvalidMonths(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	for _, months := range []int{0, -3, maxRollingMonths + 1} {
+		cost, err := svc.GetRollingCost(context.Background(), months, nil)
+
+		assert.ErrorContains(t, err, "months must be between 1 and 120")
+		assert.Nil(t, cost)
+	}
+}
+
+func TestServiceGetCostByPeriod_Deduplication(t *testing.T) {
+	const concurrency = 20
+
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog, WithCostDeduplication())
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	mockRepo.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
+		calls.Add(1)
+		<-release
+		return 500, 5, nil
+	}
+
+	results := make([]*CostResponse, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cost, err := svc.GetCostByPeriod(context.Background(), "01-2025", "12-2025", nil, nil)
+			assert.NoError(t, err)
+			results[i] = cost
+		}(i)
+	}
+
+	// Give all goroutines time to join the in-flight query.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, cost := range results {
+		assert.Equal(t, &CostResponse{TotalCost: 500, Count: 5}, cost)
+	}
+}
+
+func TestServiceGetCostByPeriod_DeduplicationKeyedByParams(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog, WithCostDeduplication())
+
+	var calls atomic.Int32
+	mockRepo.GetCostByPeriodFunc = func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
+		calls.Add(1)
+		return 0, 0, nil
+	}
+
+	netflix := "Netflix"
+	_, _ = svc.GetCostByPeriod(context.Background(), "01-2025", "", nil, nil)
+	_, _ = svc.GetCostByPeriod(context.Background(), "01-2025", "", nil, &netflix)
+	_, _ = svc.GetCostByPeriod(context.Background(), "02-2025", "", nil, nil)
+
+	assert.Equal(t, int32(3), calls.Load())
+}
+func TestServiceValidateSubscription(t *testing.T) {
+	mockRepo := &MockRepository{
+		CreateFunc: func(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error) {
+			t.Fatal("validation must not touch the repository")
+			return nil, nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{})
+
+	valid := CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       100,
+		UserID:      uuid.New(),
+		StartDate:   "01-2025",
+	}
+	assert.Empty(t, svc.ValidateSubscription(context.Background(), valid))
+
+	endDate := "2025-12"
+	invalid := CreateSubscriptionRequest{
+		Price:     -1,
+		StartDate: "01-2025",
+		EndDate:   &endDate,
+	}
+	before := testutil.ToFloat64(validationFailures.WithLabelValues("price"))
+
+	violations := svc.ValidateSubscription(context.Background(), invalid)
+
+	assert.Equal(t, []*ValidationError{
+		{Field: "service_name", Message: "service_name is required"},
+		{Field: "price", Message: "price must be greater than 0"},
+		{Field: "user_id", Message: "user_id is required and must be valid UUID"},
+		{Field: "end_date", Message: "date must be in MM-YYYY format"},
+	}, violations)
+	assert.Equal(t, before, testutil.ToFloat64(validationFailures.WithLabelValues("price")), "dry runs must not be counted")
+
+	_, err := svc.CreateSubscription(context.Background(), invalid)
+
+	assert.Error(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(validationFailures.WithLabelValues("price")))
+}
+
+func TestServiceValidateSubscription_RejectPastStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ServiceOption
+		startDate string
+		expected  []*ValidationError
+	}{
+		{name: "Past start in strict mode", opts: []ServiceOption{WithRejectPastStart()}, startDate: "05-2025", expected: []*ValidationError{{Field: "start_date", Message: ErrPastStartDate.Error()}}},
+		{name: "Current month in strict mode", opts: []ServiceOption{WithRejectPastStart()}, startDate: "06-2025"},
+		{name: "Malformed start in strict mode", opts: []ServiceOption{WithRejectPastStart()}, startDate: "2025-05", expected: []*ValidationError{{Field: "start_date", Message: "date must be in MM-YYYY format"}}},
+		{name: "Past start without strict mode", startDate: "05-2025"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(&MockRepository{}, &MockLogger{}, tt.opts...).(*service)
+			svc.now = func() time.Time { return time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC) }
+
+			violations := svc.ValidateSubscription(context.Background(), CreateSubscriptionRequest{
+				ServiceName: "Netflix",
+				Price:       100,
+				UserID:      uuid.New(),
+				StartDate:   tt.startDate,
+			})
+
+			assert.Equal(t, tt.expected, violations)
+		})
+	}
+}
+
+func TestServiceRecomputeSummaries(t *testing.T) {
+	mockRepo := &MockRepository{}
+	mockLog := &MockLogger{}
+	svc := NewService(mockRepo, mockLog)
+
+	mockRepo.RecomputeSummariesFunc = func(ctx context.Context) (int64, error) {
+		return 42, nil
+	}
+
+	result, err := svc.RecomputeSummaries(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, &RecomputeSummariesResponse{Users: 42}, result)
+
+	mockRepo.RecomputeSummariesFunc = func(ctx context.Context) (int64, error) {
+		return 0, assert.AnError
+	}
+
+	result, err = svc.RecomputeSummaries(context.Background())
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestServiceGetStats_Cache(t *testing.T) {
+	calls := 0
+	mockRepo := &MockRepository{
+		GetStatsFunc: func(ctx context.Context) (*StatsResponse, error) {
+			calls++
+			return &StatsResponse{Subscriptions: calls}, nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{}, WithStatsCache()).(*service)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	// Without a snapshot the stats are computed and cached.
+	stats, err := svc.GetStats(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Subscriptions)
+	assert.Equal(t, now, stats.ComputedAt)
+
+	now = now.Add(time.Minute)
+	stats, err = svc.GetStats(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Subscriptions, "cached snapshot must be served")
+	assert.Equal(t, now.Add(-time.Minute), stats.ComputedAt)
+	assert.Equal(t, 1, calls)
+
+	// A forced refresh recomputes and replaces the snapshot.
+	stats, err = svc.GetStats(context.Background(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Subscriptions)
+	assert.Equal(t, now, stats.ComputedAt)
+
+	stats, err = svc.GetStats(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Subscriptions)
+	assert.Equal(t, 2, calls)
+}
+
+func TestServiceGetStats_NoCache(t *testing.T) {
+	calls := 0
+	mockRepo := &MockRepository{
+		GetStatsFunc: func(ctx context.Context) (*StatsResponse, error) {
+			calls++
+			return &StatsResponse{}, nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{})
+
+	_, _ = svc.GetStats(context.Background(), false)
+	_, _ = svc.GetStats(context.Background(), false)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestServiceCreateSubscription_ServiceNamePattern(t *testing.T) {
+	newRequest := func(name string) CreateSubscriptionRequest {
+		return CreateSubscriptionRequest{ServiceName: name, Price: 100, UserID: uuid.New(), StartDate: "01-2025"}
+	}
+
+	t.Run("Default pattern", func(t *testing.T) {
+		svc := NewService(&MockRepository{}, &MockLogger{})
+
+		for _, name := range []string{"Netflix", "Yandex Plus", "Кинопоиск HD", "Disney+", "AT&T TV", "Apple Music (Family)", "Café-Premium"} {
+			_, err := svc.CreateSubscription(context.Background(), newRequest(name))
+			assert.NoError(t, err, name)
+		}
+
+		for _, name := range []string{"Music 🎵", "<script>"} {
+			_, err := svc.CreateSubscription(context.Background(), newRequest(name))
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr, name) {
+				assert.Equal(t, "service_name", validationErr.Field)
+				assert.Equal(t, "service_name contains disallowed characters", validationErr.Message)
+			}
+		}
+	})
+
+	t.Run("Configured pattern", func(t *testing.T) {
+		svc := NewService(&MockRepository{}, &MockLogger{}, WithServiceNamePattern(regexp.MustCompile(`^[A-Za-z ]+$`)))
+
+		_, err := svc.CreateSubscription(context.Background(), newRequest("Yandex Plus"))
+		assert.NoError(t, err)
+
+		_, err = svc.CreateSubscription(context.Background(), newRequest("Disney+"))
+		assert.ErrorContains(t, err, "disallowed characters")
+	})
+}
+
+func TestServiceCreateSubscription_ServiceNameContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceName string
+		pattern     *regexp.Regexp
+		message     string
+	}{
+		{name: "Control character", serviceName: "Net\x07flix", message: "service_name must not contain control characters"},
+		{name: "Trailing newline", serviceName: "Spotify\n", message: "service_name must not contain control characters"},
+		{name: "Control character allowed by pattern", serviceName: "Netflix\x00", pattern: regexp.MustCompile(`.+`), message: "service_name must not contain control characters"},
+		{name: "Emoji only", serviceName: "🎵🎬", message: "service_name must contain a letter or digit"},
+		{name: "Emoji allowed by pattern", serviceName: "🎵 🎬", pattern: regexp.MustCompile(`.+`), message: "service_name must contain a letter or digit"},
+		{name: "Whitespace only", serviceName: "   ", message: "service_name must contain a letter or digit"},
+		{name: "Punctuation only", serviceName: "--", message: "service_name must contain a letter or digit"},
+		{name: "Valid", serviceName: "Yandex Plus 2"},
+		{name: "Valid with emoji allowed by pattern", serviceName: "Music 🎵", pattern: regexp.MustCompile(`.+`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []ServiceOption
+			if tt.pattern != nil {
+				opts = append(opts, WithServiceNamePattern(tt.pattern))
+			}
+			svc := NewService(&MockRepository{}, &MockLogger{}, opts...)
+
+			_, err := svc.CreateSubscription(context.Background(), CreateSubscriptionRequest{ServiceName: tt.serviceName, Price: 100, UserID: uuid.New(), StartDate: "01-2025"})
+
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, "service_name", validationErr.Field)
+				assert.Equal(t, tt.message, validationErr.Message)
+			}
+		})
+	}
+}
+
+func TestServiceGetCostByPeriod_DatePresence(t *testing.T) {
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		field     string
+		errMsg    string
+	}{
+		{name: "Start and end", startDate: "01-2025", endDate: "12-2025"},
+		{name: "Start only", startDate: "01-2025"},
+		{name: "End only", endDate: "12-2025", field: "start_date", errMsg: "start_date is required when end_date is set"},
+		{name: "Neither", field: "start_date", errMsg: "at least one date parameter is required"},
+		{name: "End before start", startDate: "06-2025", endDate: "01-2025", field: "end_date", errMsg: "end_date must not be before start_date"},
+		{name: "Same month", startDate: "06-2025", endDate: "06-2025"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			mockRepo := &MockRepository{
+				GetCostByPeriodFunc: func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
+					called = true
+					assert.Equal(t, tt.startDate, startDate)
+					assert.Equal(t, tt.endDate, endDate)
+					return 100, 1, nil
+				},
+			}
+			svc := NewService(mockRepo, &MockLogger{})
+
+			_, err := svc.GetCostByPeriod(context.Background(), tt.startDate, tt.endDate, nil, nil)
+
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.field, validationErr.Field)
+				assert.Equal(t, tt.errMsg, validationErr.Message)
+			}
+			assert.False(t, called, "repository must not be queried")
+		})
+	}
+}
+
+func TestServiceGetCostByPeriod_MaxRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxMonths int
+		startDate string
+		endDate   string
+		wantErr   bool
+	}{
+		{name: "At the limit", maxMonths: 120, startDate: "01-2016", endDate: "12-2025"},
+		{name: "Over the limit", maxMonths: 120, startDate: "12-2015", endDate: "12-2025", wantErr: true},
+		{name: "Far over the limit", maxMonths: 120, startDate: "01-1970", endDate: "12-2099", wantErr: true},
+		{name: "Single month with limit one", maxMonths: 1, startDate: "06-2025", endDate: "06-2025"},
+		{name: "Start only is not limited", maxMonths: 1, startDate: "01-1970"},
+		{name: "No limit", startDate: "01-1970", endDate: "12-2099"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			mockRepo := &MockRepository{
+				GetCostByPeriodFunc: func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
+					called = true
+					return 100, 1, nil
+				},
+			}
+			svc := NewService(mockRepo, &MockLogger{}, WithMaxCostMonths(tt.maxMonths))
+
+			_, err := svc.GetCostByPeriod(context.Background(), tt.startDate, tt.endDate, nil, nil)
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, "end_date", validationErr.Field)
+				assert.Equal(t, "date range too large", validationErr.Message)
+			}
+			assert.False(t, called, "repository must not be queried")
+		})
+	}
+}
+
+func TestServiceGetCostWithIDs(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetCostByPeriodFunc: func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (int64, int, error) {
+			t.Fatal("the total must come from the same query as the IDs")
+			return 0, 0, nil
+		},
+		GetCostWithIDsFunc: func(ctx context.Context, startDate, endDate string, userID *uuid.UUID, serviceName *string) (*CostWithIDsResponse, error) {
+			assert.Equal(t, "01-2025", startDate)
+			assert.Equal(t, "12-2025", endDate)
+			return &CostWithIDsResponse{CostResponse: CostResponse{TotalCost: 300, Count: 3}, SubscriptionIDs: []int{4, 8, 15}}, nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{})
+
+	result, err := svc.GetCostWithIDs(context.Background(), "01-2025", "12-2025", nil, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &CostWithIDsResponse{CostResponse: CostResponse{TotalCost: 300, Count: 3}, SubscriptionIDs: []int{4, 8, 15}}, result)
+	assert.Len(t, result.SubscriptionIDs, result.Count)
+
+	_, err = svc.GetCostWithIDs(context.Background(), "", "12-2025", nil, nil)
+
+	assert.EqualError(t, err, "start_date is required when end_date is set")
+}
+
+func TestServiceGetCostByQuery(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	var got CostQuery
+	mockRepo := &MockRepository{
+		GetCostByQueryFunc: func(ctx context.Context, query CostQuery) (int64, int, error) {
+			got = query
+			return 210, 3, nil
+		},
+		GetCostByQueryWithIDsFunc: func(ctx context.Context, query CostQuery) (*CostWithIDsResponse, error) {
+			return &CostWithIDsResponse{CostResponse: CostResponse{TotalCost: 210, Count: 3}, SubscriptionIDs: []int{1, 2, 4}}, nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{}).(*service)
+	svc.now = func() time.Time { return time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC) }
+
+	query := CostQuery{StartDate: "01-2025", EndDate: "now", UserIDs: []uuid.UUID{alice, bob}, ServiceNames: []string{"Netflix", "Spotify"}}
+	result, err := svc.GetCostByQuery(context.Background(), query)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &CostWithIDsResponse{CostResponse: CostResponse{TotalCost: 210, Count: 3}}, result)
+	assert.Equal(t, CostQuery{StartDate: "01-2025", EndDate: "06-2025", UserIDs: []uuid.UUID{alice, bob}, ServiceNames: []string{"Netflix", "Spotify"}}, got)
+
+	query.IncludeIDs = true
+	result, err = svc.GetCostByQuery(context.Background(), query)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 4}, result.SubscriptionIDs)
+
+	tests := []struct {
+		name    string
+		query   CostQuery
+		message string
+	}{
+		{name: "Missing start date", query: CostQuery{EndDate: "12-2025"}, message: "start_date is required when end_date is set"},
+		{name: "End before start", query: CostQuery{StartDate: "12-2025", EndDate: "01-2025"}, message: "end_date must not be before start_date"},
+		{name: "Nil user ID", query: CostQuery{StartDate: "01-2025", UserIDs: []uuid.UUID{alice, uuid.Nil}}, message: "user_ids must be valid UUIDs"},
+		{name: "Empty service name", query: CostQuery{StartDate: "01-2025", ServiceNames: []string{"Netflix", ""}}, message: "service_names must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetCostByQuery(context.Background(), tt.query)
+
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.message, validationErr.Message)
+			}
+		})
+	}
+}
+
+func TestServicePreviewCostChange(t *testing.T) {
+	// Three subscriptions match the filter: two to Netflix at 100 and one to
+	// Spotify at 150.
+	mockRepo := &MockRepository{
+		GetCostByQueryFunc: func(ctx context.Context, query CostQuery) (int64, int, error) {
+			if slices.Equal(query.ServiceNames, []string{"Netflix"}) {
+				return 200, 2, nil
+			}
+			return 350, 3, nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{})
+
+	tests := []struct {
+		name     string
+		filter   CostQuery
+		change   PriceChange
+		expected *CostPreviewResponse
+	}{
+		{
+			name:     "Increase",
+			filter:   CostQuery{StartDate: "01-2025"},
+			change:   PriceChange{ServiceName: "Netflix", Price: 130},
+			expected: &CostPreviewResponse{TotalCost: 350, ProjectedCost: 410, Delta: 60, Count: 3, Changed: 2},
+		},
+		{
+			name:     "Decrease",
+			filter:   CostQuery{StartDate: "01-2025", ServiceNames: []string{"Netflix", "Spotify"}},
+			change:   PriceChange{ServiceName: "Netflix", Price: 80},
+			expected: &CostPreviewResponse{TotalCost: 350, ProjectedCost: 310, Delta: -40, Count: 3, Changed: 2},
+		},
+		{
+			name:     "Service filtered out",
+			filter:   CostQuery{StartDate: "01-2025", ServiceNames: []string{"Spotify"}},
+			change:   PriceChange{ServiceName: "Netflix", Price: 130},
+			expected: &CostPreviewResponse{TotalCost: 350, ProjectedCost: 350, Count: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := svc.PreviewCostChange(context.Background(), CostPreviewRequest{Filter: tt.filter, Change: tt.change})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, preview)
+		})
+	}
+}
+
+func TestServicePreviewCostChange_Validation(t *testing.T) {
+	svc := NewService(&MockRepository{}, &MockLogger{})
+
+	tests := []struct {
+		name    string
+		req     CostPreviewRequest
+		message string
+	}{
+		{name: "Invalid filter", req: CostPreviewRequest{Filter: CostQuery{EndDate: "12-2025"}, Change: PriceChange{ServiceName: "Netflix", Price: 130}}, message: "start_date is required when end_date is set"},
+		{name: "Missing service name", req: CostPreviewRequest{Filter: CostQuery{StartDate: "01-2025"}, Change: PriceChange{ServiceName: " ", Price: 130}}, message: "change.service_name is required"},
+		{name: "Non-positive price", req: CostPreviewRequest{Filter: CostQuery{StartDate: "01-2025"}, Change: PriceChange{ServiceName: "Netflix"}}, message: "change.price must be greater than 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.PreviewCostChange(context.Background(), tt.req)
+
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.message, validationErr.Message)
+			}
+		})
+	}
+}
+
+func TestServiceListSubscriptions_HardCap(t *testing.T) {
+	tests := []struct {
+		name      string
+		cap       int
+		rows      int
+		limit     int
+		expected  int
+		truncated bool
+	}{
+		{name: "Below the cap", cap: 3, rows: 2, limit: 4, expected: 2},
+		{name: "At the cap", cap: 3, rows: 3, limit: 4, expected: 3},
+		{name: "Over the cap", cap: 3, rows: 4, limit: 4, expected: 3, truncated: true},
+		{name: "No cap", rows: 5, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotLimit int
+			mockRepo := &MockRepository{
+				GetAllFunc: func(ctx context.Context, sort Sort, limit int) ([]Subscription, error) {
+					gotLimit = limit
+					return make([]Subscription, tt.rows), nil
+				},
+			}
+			svc := NewService(mockRepo, &MockLogger{}, WithListHardCap(tt.cap))
+
+			list, err := svc.ListSubscriptions(context.Background(), Sort{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.limit, gotLimit)
+			assert.Len(t, list.Subscriptions, tt.expected)
+			assert.Equal(t, tt.truncated, list.Truncated)
+		})
+	}
+}
+
+func TestServiceGetAllSubscriptions_IgnoresHardCap(t *testing.T) {
+	var gotLimit int
+	mockRepo := &MockRepository{
+		GetAllFunc: func(ctx context.Context, sort Sort, limit int) ([]Subscription, error) {
+			gotLimit = limit
+			return make([]Subscription, 5), nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{}, WithListHardCap(3))
+
+	subs, err := svc.GetAllSubscriptions(context.Background(), Sort{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, gotLimit)
+	assert.Len(t, subs, 5)
+}
+
+func TestServiceSubscriptions_Active(t *testing.T) {
+	pastEnd, currentEnd, futureEnd := "05-2025", "06-2025", "12-2026"
+	subs := []Subscription{
+		{ID: 1, StartDate: "01-2020"},
+		{ID: 2, StartDate: "01-2025", EndDate: &futureEnd},
+		{ID: 3, StartDate: "01-2025", EndDate: &currentEnd},
+		{ID: 4, StartDate: "01-2024", EndDate: &pastEnd},
+	}
+	expected := map[int]bool{1: true, 2: true, 3: true, 4: false}
+
+	mockRepo := &MockRepository{
+		GetAllFunc: func(ctx context.Context, sort Sort, limit int) ([]Subscription, error) {
+			return append([]Subscription(nil), subs...), nil
+		},
+		GetByIDFunc: func(ctx context.Context, id int) (*Subscription, error) {
+			sub := subs[id-1]
+			return &sub, nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{}).(*service)
+	svc.now = func() time.Time { return time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC) }
+
+	list, err := svc.ListSubscriptions(context.Background(), Sort{})
+	assert.NoError(t, err)
+	for _, sub := range list.Subscriptions {
+		assert.Equal(t, expected[sub.ID], sub.Active, "subscription %d", sub.ID)
+	}
+
+	for id, active := range expected {
+		sub, err := svc.GetSubscriptionByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, active, sub.Active, "subscription %d", id)
+	}
+}
+
+func TestSubscriptionNextBillingDate(t *testing.T) {
+	month := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		expected  string
+	}{
+		{name: "Started", startDate: "01-2024", expected: "07-2025"},
+		{name: "Starting this month", startDate: "06-2025", expected: "07-2025"},
+		{name: "Not started yet", startDate: "09-2025", expected: "09-2025"},
+		{name: "Ending later", startDate: "01-2025", endDate: "12-2025", expected: "07-2025"},
+		{name: "Ending next month", startDate: "01-2025", endDate: "07-2025", expected: "07-2025"},
+		{name: "Ending this month", startDate: "01-2025", endDate: "06-2025"},
+		{name: "Expired", startDate: "01-2024", endDate: "05-2025"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := Subscription{StartDate: tt.startDate}
+			if tt.endDate != "" {
+				sub.EndDate = &tt.endDate
+			}
+
+			next := sub.nextBillingDate(month)
+
+			if tt.expected == "" {
+				assert.Nil(t, next)
+				return
+			}
+			if assert.NotNil(t, next) {
+				assert.Equal(t, tt.expected, *next)
+			}
+		})
+	}
+}
+
+func TestServiceGetSubscriptionByID_NextBillingDate(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetByIDFunc: func(ctx context.Context, id int) (*Subscription, error) {
+			return &Subscription{ID: id, StartDate: "01-2025"}, nil
+		},
+	}
+	svc := NewService(mockRepo, &MockLogger{}).(*service)
+	svc.now = func() time.Time { return time.Date(2025, time.December, 31, 23, 0, 0, 0, time.UTC) }
+
+	sub, err := svc.GetSubscriptionByID(context.Background(), 1)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, sub.NextBillingDate) {
+		assert.Equal(t, "01-2026", *sub.NextBillingDate)
+	}
+}
+
+func TestServiceGetUsers_Pagination(t *testing.T) {
+	mockRepo := &MockRepository{}
+	svc := NewService(mockRepo, &MockLogger{})
+
+	var gotLimit, gotOffset int
+	mockRepo.GetUsersFunc = func(ctx context.Context, limit, offset int) ([]UserSubscriptions, int, error) {
+		gotLimit, gotOffset = limit, offset
+		return []UserSubscriptions{}, 0, nil
+	}
+
+	_, err := svc.GetUsers(context.Background(), 0, 40)
+
+	assert.NoError(t, err)
+	assert.Equal(t, defaultPageLimit, gotLimit)
+	assert.Equal(t, 40, gotOffset)
+
+	_, err = svc.GetUsers(context.Background(), maxPageLimit+1, 0)
+	assert.ErrorContains(t, err, "limit must be between 1 and 100")
+}
+
+func TestServiceGetServiceDiversity_Pagination(t *testing.T) {
+	mockRepo := &MockRepository{}
+	svc := NewService(mockRepo, &MockLogger{})
+
+	userID := uuid.New()
+	var gotLimit, gotOffset int
+	mockRepo.GetServiceDiversityFunc = func(ctx context.Context, limit, offset int) ([]UserServices, int, error) {
+		gotLimit, gotOffset = limit, offset
+		return []UserServices{{UserID: userID, DistinctServices: 2}}, 41, nil
+	}
+
+	page, err := svc.GetServiceDiversity(context.Background(), 0, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Page[UserServices]{Items: []UserServices{{UserID: userID, DistinctServices: 2}}, Total: 41, Limit: defaultPageLimit, Offset: 20, NextCursor: "21"}, page)
+	assert.Equal(t, defaultPageLimit, gotLimit)
+	assert.Equal(t, 20, gotOffset)
+
+	_, err = svc.GetServiceDiversity(context.Background(), 10, -1)
+	assert.Error(t, err)
+}
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      []int
+		total      int
+		offset     int
+		nextCursor string
+	}{
+		{"first page", []int{1, 2}, 5, 0, "2"},
+		{"middle page", []int{3, 4}, 5, 2, "4"},
+		{"last page", []int{5}, 5, 4, ""},
+		{"past the end", []int{}, 5, 10, ""},
+		{"empty listing", []int{}, 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := newPage(tt.items, tt.total, 2, tt.offset)
+
+			assert.Equal(t, &Page[int]{Items: tt.items, Total: tt.total, Limit: 2, Offset: tt.offset, NextCursor: tt.nextCursor}, page)
+		})
+	}
+}
+
+func TestServiceGetChangesSince(t *testing.T) {
+	mockRepo := &MockRepository{}
+	svc := NewService(mockRepo, &MockLogger{})
+
+	var gotSince time.Time
+	mockRepo.GetChangedSinceFunc = func(ctx context.Context, since time.Time) ([]Subscription, error) {
+		gotSince = since
+		return []Subscription{{ID: 1, StartDate: "01-2025"}}, nil
+	}
+
+	subs, err := svc.GetChangesSince(context.Background(), "2025-06-01T12:00:00+03:00")
+
+	assert.NoError(t, err)
+	assert.True(t, gotSince.Equal(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)))
+	if assert.Len(t, subs, 1) {
+		assert.True(t, subs[0].Active)
+	}
+
+	tests := []struct {
+		name  string
+		since string
+		want  string
+	}{
+		{"missing", "", "since is required"},
+		{"date only", "2025-06-01", "since must be an RFC 3339 timestamp"},
+		{"garbage", "yesterday", "since must be an RFC 3339 timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetChangesSince(context.Background(), tt.since)
+
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, "since", validationErr.Field)
+				assert.Equal(t, tt.want, validationErr.Message)
+			}
+		})
+	}
+}
+
+func TestServiceGetMeta(t *testing.T) {
+	svc := NewService(&MockRepository{}, &MockLogger{},
+		WithServiceNamePattern(regexp.MustCompile(`^[A-Za-z ]+$`)),
+		WithMaxCostMonths(24),
+	)
+
+	meta := svc.GetMeta(context.Background())
+
+	assert.Equal(t, &MetaResponse{
+		DateFormat:         "MM-YYYY",
+		ServiceNamePattern: `^[A-Za-z ]+$`,
+		SortFields:         []string{"created_at", "end_date", "id", "price", "service_name", "start_date", "updated_at"},
+		SortOrders:         []string{"asc", "desc"},
+		MaxPageLimit:       maxPageLimit,
+		MaxCostMonths:      24,
+	}, meta)
+
+	meta = NewService(&MockRepository{}, &MockLogger{}).GetMeta(context.Background())
+	assert.Equal(t, defaultServiceNamePattern.String(), meta.ServiceNamePattern)
+	assert.Zero(t, meta.MaxCostMonths)
+}
+
+func TestService_LogsDuration(t *testing.T) {
+	mockRepo := &MockRepository{}
+	log := &recordingLogger{}
+	svc := NewService(mockRepo, log)
+
+	const delay = 20 * time.Millisecond
+	mockRepo.GetByIDFunc = func(ctx context.Context, id int) (*Subscription, error) {
+		time.Sleep(delay)
+		switch id {
+		case 2:
+			return nil, ErrNotFound
+		case 3:
+			return nil, errors.New("connection reset")
+		}
+		return &Subscription{ID: id, StartDate: "01-2025"}, nil
+	}
+
+	_, err := svc.GetSubscriptionByID(context.Background(), 1)
+	assert.NoError(t, err)
+	_, err = svc.GetSubscriptionByID(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.GetSubscriptionByID(context.Background(), 3)
+	assert.Error(t, err)
+
+	if !assert.Len(t, log.entries, 3) {
+		return
+	}
+	for i, level := range []string{"info", "warn", "error"} {
+		entry := log.entries[i]
+		assert.Equal(t, level, entry.level)
+		assert.Equal(t, "GetSubscriptionByID", entry.fields["operation"])
+		if assert.IsType(t, int64(0), entry.fields["duration_ms"]) {
+			assert.GreaterOrEqual(t, entry.fields["duration_ms"].(int64), delay.Milliseconds())
+		}
+	}
+	assert.ErrorIs(t, log.entries[1].fields["error"].(error), ErrNotFound)
+}
+
+func TestIsClientError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Validation error", err: &ValidationError{Field: "price", Message: "price must be greater than 0"}, expected: true},
+		{name: "Wrapped not found", err: fmt.Errorf("lookup: %w", ErrNotFound), expected: true},
+		{name: "External ID taken", err: ErrExternalIDExists, expected: true},
+		{name: "Past start", err: ErrPastStartDate, expected: true},
+		{name: "Database unavailable", err: ErrUnavailable},
+		{name: "Query failure", err: errors.New("failed to calculate cost")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isClientError(tt.err))
+		})
+	}
+}
+
+func TestServiceGetBudgetStatus(t *testing.T) {
+	mockRepo := &MockRepository{}
+	log := &recordingLogger{}
+	svc := NewService(mockRepo, log)
+
+	mockRepo.GetBudgetCostsFunc = func(ctx context.Context) ([]BudgetStatus, error) {
+		return []BudgetStatus{
+			{ServiceName: "Hulu", MonthlyCap: 500, MonthlyCost: 650},
+			{ServiceName: "Netflix", MonthlyCap: 800, MonthlyCost: 800},
+			{ServiceName: "Spotify", MonthlyCap: 300, MonthlyCost: 120},
+		}, nil
+	}
+
+	budgets, err := svc.GetBudgetStatus(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []BudgetStatus{
+		{ServiceName: "Hulu", MonthlyCap: 500, MonthlyCost: 650, OverBudget: true, Overage: 150},
+		{ServiceName: "Netflix", MonthlyCap: 800, MonthlyCost: 800},
+		{ServiceName: "Spotify", MonthlyCap: 300, MonthlyCost: 120},
+	}, budgets)
+
+	_, err = svc.GetBudgetStatus(context.Background())
+	assert.NoError(t, err)
+
+	var events []logEntry
+	for _, entry := range log.entries {
+		if entry.level == "warn" {
+			events = append(events, entry)
+		}
+	}
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "Budget exceeded", events[0].message)
+		assert.Equal(t, map[string]any{
+			"event":        "budget.exceeded",
+			"service_name": "Hulu",
+			"monthly_cap":  500,
+			"monthly_cost": 650,
+			"overage":      150,
+		}, events[0].fields)
+	}
+}
+
+func TestServiceGetBudgetStatus_CrossedAgain(t *testing.T) {
+	mockRepo := &MockRepository{}
+	log := &recordingLogger{}
+	svc := NewService(mockRepo, log)
+
+	costs := []int{900, 400, 1000}
+	mockRepo.GetBudgetCostsFunc = func(ctx context.Context) ([]BudgetStatus, error) {
+		cost := costs[0]
+		costs = costs[1:]
+		return []BudgetStatus{{ServiceName: "Netflix", MonthlyCap: 800, MonthlyCost: cost}}, nil
+	}
+
+	before := testutil.ToFloat64(budgetExceeded.WithLabelValues("Netflix"))
+	for range 3 {
+		_, err := svc.GetBudgetStatus(context.Background())
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(budgetExceeded.WithLabelValues("Netflix"))-before)
+}
+
+func TestServiceGetBudgetStatus_Error(t *testing.T) {
+	mockRepo := &MockRepository{}
+	svc := NewService(mockRepo, &MockLogger{})
+
+	mockRepo.GetBudgetCostsFunc = func(ctx context.Context) ([]BudgetStatus, error) {
+		return nil, assert.AnError
+	}
+
+	_, err := svc.GetBudgetStatus(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,41 @@
+package subscriptions
+
+import (
+	"context"
+	"time"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// StatsRefresher periodically recomputes the stats snapshot served by a
+// service created with WithStatsCache.
+type StatsRefresher struct {
+	service  SubscriptionService
+	log      logger.LoggerInterface
+	interval time.Duration
+}
+
+func NewStatsRefresher(service SubscriptionService, log logger.LoggerInterface, interval time.Duration) *StatsRefresher {
+	return &StatsRefresher{service: service, log: log, interval: interval}
+}
+
+// Run refreshes immediately and then every interval until ctx is cancelled.
+func (r *StatsRefresher) Run(ctx context.Context) {
+	r.log.Info("Stats refresher started", map[string]any{"interval": r.interval})
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.service.GetStats(ctx, true); err != nil && ctx.Err() == nil {
+			r.log.Error("Failed to refresh stats", map[string]any{"error": err})
+		}
+
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stats refresher stopped", nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package subscriptions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsRefresher_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	refreshed := make(chan bool, 10)
+	mockService := &MockService{
+		GetStatsFunc: func(ctx context.Context, fresh bool) (*StatsResponse, error) {
+			refreshed <- fresh
+			return &StatsResponse{}, nil
+		},
+	}
+	refresher := NewStatsRefresher(mockService, &MockLogger{}, 10*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		refresher.Run(ctx)
+		close(done)
+	}()
+
+	// Refreshes immediately and then on every tick.
+	for range 2 {
+		select {
+		case fresh := <-refreshed:
+			assert.True(t, fresh)
+		case <-time.After(time.Second):
+			t.Fatal("stats were not refreshed")
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("refresher did not stop on cancellation")
+	}
+}
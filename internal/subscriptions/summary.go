@@ -0,0 +1,40 @@
+package subscriptions
+
+import (
+	"context"
+	"time"
+
+	"github.com/n-korel/user-subscriptions-api/internal/logger"
+)
+
+// SummaryRefresher periodically recomputes the per-user cost summaries.
+type SummaryRefresher struct {
+	service  SubscriptionService
+	log      logger.LoggerInterface
+	interval time.Duration
+}
+
+func NewSummaryRefresher(service SubscriptionService, log logger.LoggerInterface, interval time.Duration) *SummaryRefresher {
+	return &SummaryRefresher{service: service, log: log, interval: interval}
+}
+
+// Run recomputes immediately and then every interval until ctx is cancelled.
+func (r *SummaryRefresher) Run(ctx context.Context) {
+	r.log.Info("Summary refresher started", map[string]any{"interval": r.interval})
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.service.RecomputeSummaries(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error("Failed to recompute summaries", map[string]any{"error": err})
+		}
+
+		select {
+		case <-ctx.Done():
+			r.log.Info("Summary refresher stopped", nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
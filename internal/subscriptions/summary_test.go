@@ -0,0 +1,42 @@
+package subscriptions
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSummaryRefresher_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	recomputed := make(chan struct{}, 10)
+	mockService := &MockService{
+		RecomputeSummariesFunc: func(ctx context.Context) (*RecomputeSummariesResponse, error) {
+			recomputed <- struct{}{}
+			return &RecomputeSummariesResponse{}, nil
+		},
+	}
+	refresher := NewSummaryRefresher(mockService, &MockLogger{}, 10*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		refresher.Run(ctx)
+		close(done)
+	}()
+
+	// Recomputes immediately and then on every tick.
+	for range 2 {
+		select {
+		case <-recomputed:
+		case <-time.After(time.Second):
+			t.Fatal("summaries were not recomputed")
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("refresher did not stop on cancellation")
+	}
+}
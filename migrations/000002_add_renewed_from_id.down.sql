@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_subscriptions_renewed_from_id;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS renewed_from_id;
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS renewed_from_id INTEGER REFERENCES subscriptions(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_renewed_from_id ON subscriptions(renewed_from_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_subscriptions_external_id;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_cost_summary (
+    user_id UUID PRIMARY KEY,
+    monthly_cost INTEGER NOT NULL,
+    active_subscriptions INTEGER NOT NULL,
+    computed_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
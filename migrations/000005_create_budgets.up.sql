@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS budgets (
+    service_name VARCHAR(255) PRIMARY KEY,
+    monthly_cap INTEGER NOT NULL CHECK (monthly_cap >= 0)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_subscriptions_created_at_id;
+DROP INDEX IF EXISTS idx_subscriptions_service_name_start_date;
+DROP INDEX IF EXISTS idx_subscriptions_user_id_start_date;
+DROP INDEX IF EXISTS idx_subscriptions_end_month;
+DROP INDEX IF EXISTS idx_subscriptions_start_month;
+DROP FUNCTION IF EXISTS month_date(TEXT);
@@ -0,0 +1,11 @@
+-- to_date is only STABLE and cannot be indexed. month_date is the same
+-- conversion declared IMMUTABLE, which holds for the fixed MM-YYYY format.
+CREATE OR REPLACE FUNCTION month_date(month TEXT) RETURNS DATE
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+    AS $$ SELECT to_date(month, 'MM-YYYY') $$;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_start_month ON subscriptions(month_date(start_date));
+CREATE INDEX IF NOT EXISTS idx_subscriptions_end_month ON subscriptions(month_date(end_date));
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_id_start_date ON subscriptions(user_id, month_date(start_date));
+CREATE INDEX IF NOT EXISTS idx_subscriptions_service_name_start_date ON subscriptions(service_name, month_date(start_date));
+CREATE INDEX IF NOT EXISTS idx_subscriptions_created_at_id ON subscriptions(created_at DESC, id DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_subscriptions_reference_code;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS reference_code;
@@ -0,0 +1,6 @@
+-- Reference codes are generated by the database, so every insert path gets one.
+-- The first 12 hex digits of a v4 UUID are random.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS reference_code TEXT NOT NULL
+    DEFAULT 'SUB-' || upper(substr(replace(gen_random_uuid()::text, '-', ''), 1, 12));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_reference_code ON subscriptions(reference_code);